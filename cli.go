@@ -0,0 +1,376 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/output"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+)
+
+// globalFlags are accepted by every command.
+type globalFlags struct {
+	configFile string
+	dataDir    string
+}
+
+var globals globalFlags
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:   "polymarket-tool",
+		Short: "Real-time detection of large trades on Polymarket",
+		Long: `Polymarket Tool - Real-time detection of large trades on Polymarket
+
+Settings come from environment variables, optionally read from a --config
+file; see 'polymarket-tool help environment'.`,
+		Example: `  polymarket-tool markets fed                     # Search and add markets
+  polymarket-tool add-market fed-decision         # Add by slug
+  polymarket-tool discover-whales top10           # Track top 10 traders
+  polymarket-tool fat-trades --min-usd 500        # Find trades > $500
+  polymarket-tool start                           # Start real-time tracking
+  polymarket-tool dashboard                       # Watch it all in a terminal UI
+  MIN_TRADE_USD=100 polymarket-tool start         # Custom threshold`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyGlobals(); err != nil {
+				return err
+			}
+			cfg := config.Load()
+			return logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+		},
+	}
+
+	root.PersistentFlags().StringVar(&globals.configFile, "config", "", "read environment variables from this KEY=VALUE file")
+	root.PersistentFlags().StringVar(&globals.dataDir, "data-dir", "", "directory for whales, markets and other data files (default: DATA_DIR or data)")
+	root.MarkPersistentFlagFilename("config")
+	root.MarkPersistentFlagDirname("data-dir")
+
+	root.AddCommand(
+		newStartCmd(),
+		newDashboardCmd(),
+		newMarketsCmd(),
+		newAddMarketCmd(),
+		newFatTradesCmd(),
+		newDiscoverWhalesCmd(),
+		newWhaleTradesCmd(),
+		newListCmd(),
+		newMuteCmd(),
+		newUnmuteCmd(),
+		newMutesCmd(),
+		newReportCmd(),
+		newDoctorCmd(),
+		newTestAlertCmd(),
+		newEnvironmentHelp(),
+	)
+	return root
+}
+
+// applyGlobals loads the --config file into the environment and points
+// storage at the data directory. Flags win over the environment, which wins
+// over the file.
+func applyGlobals() error {
+	if globals.configFile != "" {
+		if err := config.LoadFile(globals.configFile); err != nil {
+			return err
+		}
+	}
+
+	dir := globals.dataDir
+	if dir == "" {
+		dir = os.Getenv("DATA_DIR")
+	}
+	if dir == "" {
+		dir = storage.DefaultDir
+	}
+	storage.SetDir(dir)
+	return nil
+}
+
+// newEnvironmentHelp is a help topic, shown by 'help environment'.
+func newEnvironmentHelp() *cobra.Command {
+	return &cobra.Command{
+		Use:   "environment",
+		Short: "Environment variables read by every command",
+		Long: `Environment variables, which may also be set in a --config file:
+
+  DATA_DIR                Directory for data files (default: data)
+  MIN_TRADE_USD           Minimum trade value (default: 1000)
+  MIN_LIQUIDITY_RATIO     Min trade as % of orderbook (default: 0.05)
+  CRITICAL_TRADE_USD      Trade value marked critical severity (default: 50000)
+  WEBHOOK_URL             Discord/Slack webhook for notifications
+  WEBHOOK_FORMAT          Preset for webhook text instead of the embed
+  WEBHOOK_TEMPLATE        Go text/template file for webhook text
+  JSON_WEBHOOK_URL        Generic webhook receiving the versioned JSON payload
+  JSON_WEBHOOK_SECRET     HMAC-SHA256 signing secret for JSON_WEBHOOK_URL
+  JSON_WEBHOOK_TEMPLATE   Go text/template file overriding the JSON body
+  PAGERDUTY_ROUTING_KEY   PagerDuty Events v2 routing key (sink "pagerduty")
+  OPSGENIE_API_KEY        Opsgenie API key (sink "opsgenie")
+  INCIDENT_RESOLVE_AFTER  Auto-resolve incidents after this quiet period (default: 30m)
+  INCIDENT_MIN_SEVERITY   Lowest detection severity that opens an incident (default: critical)
+  MQTT_URL                MQTT broker for detections, e.g. tcp://localhost:1883
+  NATS_URL                NATS server for detections, e.g. nats://localhost:4222
+  MQTT_TRADES/NATS_TRADES Also publish every trade on watched markets
+  EXEC_COMMAND            Shell command run per detection (JSON on stdin)
+  EXEC_TIMEOUT            Kill EXEC_COMMAND after this long (default: 10s)
+  EXEC_CONCURRENCY        Max EXEC_COMMAND runs in flight (default: 4)
+  LOG_LEVEL               debug, info, warn or error (default: info)
+  LOG_FORMAT              text or json (default: text)
+  TRACE_EXPORTER          otlp, stdout or none (default: none)
+  TRACE_ENDPOINT          OTLP/HTTP collector, e.g. localhost:4318
+  TRACE_SAMPLE_RATIO      Fraction of traces kept (default: 1)
+  CONSOLE_FORMAT          Console preset: verbose, compact, ndjson (default: verbose)
+  CONSOLE_TEMPLATE        Go text/template file for console output
+  MARKET_COOLDOWN         Collapse repeat alerts per market side, e.g. 2m
+  WALLET_COOLDOWN         Collapse repeat alerts per wallet, e.g. 10m
+  DEDUPE_TTL              How long trade hashes are remembered (default: 6h)
+  DETECTIONS_MAX_MB       Size at which detections.jsonl is rotated (default: 64)
+  PIPELINE_WORKERS        Detection workers (default: 4)
+  PIPELINE_QUEUE_SIZE     Trades/detections queued per stage (default: 1024)
+  PIPELINE_OVERFLOW       drop-oldest or block when a queue is full (default: drop-oldest)
+  HEALTH_STALE_AFTER      Unhealthy after the feed is silent this long (default: 5m)
+  HEALTH_ALERTS           Send unhealthy/recovered messages (default: true)
+  HEALTH_SINKS            Comma-separated sinks for health messages (default: all)
+  HTTP_TOKEN              Bearer token required by --http to change markets and whales
+  SHUTDOWN_GRACE          Time allowed to drain queues on shutdown (default: 30s)
+  REPORT_DAILY            Send a daily report from start at HH:MM
+  REPORT_WEEKLY           Send a weekly report from start at "<weekday> HH:MM"
+  REPORT_SINKS            Comma-separated sinks for scheduled reports
+  REPORT_DIR              Directory to also write scheduled reports to
+  REPORT_FORMATS          Formats written to REPORT_DIR (default: markdown)
+  SEARCH_QUERIES          Comma-separated market search terms
+
+A --config file holds the same variables, one KEY=VALUE per line:
+
+  # polymarket.env
+  MIN_TRADE_USD=500
+  WEBHOOK_URL="https://discord.com/api/webhooks/..."
+
+Variables set in the environment take precedence over the file.`,
+	}
+}
+
+// confirm asks a yes/no question on stdin unless yes is already set. When
+// stdin is not a terminal, or gives no answer, it refuses.
+func confirm(question string, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !stdinIsTerminal() {
+		return fmt.Errorf("stdin is not a terminal; pass --yes to confirm")
+	}
+	fmt.Printf("%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return fmt.Errorf("no answer on stdin; pass --yes to confirm")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("cancelled")
+}
+
+// stdinIsTerminal reports whether stdin can answer a prompt, so commands run
+// from cron or a pipe fail fast instead of waiting for input.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// prompt prints question and reads one trimmed line from stdin. Callers
+// check stdinIsTerminal first.
+func prompt(question string) string {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// dayDuration is a duration flag that also accepts days, e.g. 7d.
+type dayDuration time.Duration
+
+func (d *dayDuration) String() string {
+	if *d == 0 {
+		return ""
+	}
+	return time.Duration(*d).String()
+}
+
+func (d *dayDuration) Set(s string) error {
+	v, err := parseDuration(s)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid duration %q (use e.g. 30m, 6h, 7d)", s)
+	}
+	*d = dayDuration(v)
+	return nil
+}
+
+func (d *dayDuration) Type() string { return "duration" }
+
+// dateValue is a date flag, e.g. 2026-12-31, also accepting RFC 3339 times.
+type dateValue time.Time
+
+func (d *dateValue) String() string {
+	if time.Time(*d).IsZero() {
+		return ""
+	}
+	return time.Time(*d).Format("2006-01-02")
+}
+
+func (d *dateValue) Set(s string) error {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return fmt.Errorf("invalid date %q (use e.g. 2026-12-31)", s)
+		}
+	}
+	*d = dateValue(t)
+	return nil
+}
+
+func (d *dateValue) Type() string { return "date" }
+
+// ============= COMPLETION =============
+
+// completionFunc completes a positional argument or flag value.
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeMarketSlugs offers saved market slugs, described by their titles.
+func completeMarketSlugs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if applyGlobals() != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	markets, _ := storage.LoadMarkets()
+	var out []string
+	for _, m := range markets {
+		out = append(out, m.Slug+"\t"+m.Title)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWhaleNames offers tracked whale names.
+func completeWhaleNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if applyGlobals() != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	whales, _ := storage.LoadWhales()
+	var out []string
+	for _, w := range whales {
+		if w.Name != "" {
+			out = append(out, w.Name+"\t"+w.Address)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWhaleAddresses offers tracked whale addresses, described by name.
+func completeWhaleAddresses(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if applyGlobals() != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	whales, _ := storage.LoadWhales()
+	var out []string
+	for _, w := range whales {
+		out = append(out, w.Address+"\t"+w.Name)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeMutes offers the targets of active mutes of kind.
+func completeMutes(kind string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if applyGlobals() != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		mutes, _ := storage.LoadMutes()
+		now := time.Now()
+		var out []string
+		for _, m := range mutes {
+			if m.Kind == kind && !m.Expired(now) {
+				out = append(out, m.Target)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// requireSubcommand is the RunE of commands that only group subcommands, so
+// a missing or misspelt subcommand fails instead of printing help.
+func requireSubcommand(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unknown subcommand %q for %q", args[0], cmd.CommandPath())
+	}
+	var names []string
+	for _, c := range cmd.Commands() {
+		if c.IsAvailableCommand() {
+			names = append(names, c.Name())
+		}
+	}
+	return fmt.Errorf("%q needs a subcommand: %s", cmd.CommandPath(), strings.Join(names, ", "))
+}
+
+// firstArg limits a completion function to the first positional argument.
+func firstArg(complete completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// fixedValues completes a flag or argument from a fixed list.
+func fixedValues(values ...string) completionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// outputFormat is the --format flag of read commands, validated as it is set.
+type outputFormat string
+
+func (f *outputFormat) String() string { return string(*f) }
+
+func (f *outputFormat) Set(s string) error {
+	if err := output.Validate(s); err != nil {
+		return err
+	}
+	*f = outputFormat(s)
+	return nil
+}
+
+func (f *outputFormat) Type() string { return "format" }
+
+// machine reports whether stdout carries data rather than a table.
+func (f outputFormat) machine() bool {
+	return f != "" && f != output.Table
+}
+
+// status is where a command prints headings and notes: stdout alongside a
+// table, stderr when stdout is for data.
+func (f outputFormat) status() io.Writer {
+	if f.machine() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// write prints rows to stdout in the machine-readable format.
+func (f outputFormat) write(rows interface{}) error {
+	return output.Write(os.Stdout, string(f), rows)
+}
+
+// addFormatFlag registers --format on a read command.
+func addFormatFlag(cmd *cobra.Command, f *outputFormat) {
+	*f = output.Table
+	cmd.Flags().Var(f, "format", "output format: table, json, ndjson or csv")
+	cmd.RegisterFlagCompletionFunc("format", fixedValues(output.Formats...))
+}
@@ -1,30 +1,65 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
 	"github.com/mikefdy/polymarket-tool/internal/types"
 )
 
 type Client struct {
 	cfg    *config.Config
 	http   *http.Client
+	ctx    context.Context
+	health *healthTracker
+}
+
+// Health is the recent request outcome of one API (gamma, clob or data).
+type Health struct {
+	LastSuccess         time.Time `json:"lastSuccess"`
+	LastFailure         time.Time `json:"lastFailure"`
+	LastError           string    `json:"lastError,omitempty"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+}
+
+// healthTracker is shared by every copy of a Client.
+type healthTracker struct {
+	mu   sync.Mutex
+	apis map[string]*Health
 }
 
 func New(cfg *config.Config) *Client {
 	return &Client{
-		cfg: cfg,
-		http: &http.Client{Timeout: 30 * time.Second},
+		cfg:    cfg,
+		http:   &http.Client{Timeout: 30 * time.Second},
+		health: &healthTracker{apis: make(map[string]*Health)},
 	}
 }
 
+// WithContext returns a copy of c whose requests use ctx, so they are
+// cancelled with it and traced as its children.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
 func (c *Client) SearchMarkets(query string) ([]types.Market, error) {
 	// Use the proper public-search endpoint
 	// Filter to only show active events
@@ -46,7 +81,17 @@ func (c *Client) SearchMarkets(query string) ([]types.Market, error) {
 				continue // Skip events older than 18 months
 			}
 		}
-		markets = append(markets, event.Markets...)
+		for _, m := range event.Markets {
+			// Carry the event's totals, which search results don't nest
+			// under each market.
+			if len(m.Events) == 0 {
+				m.Events = []types.MarketEvent{{Slug: event.Slug, Title: event.Title}}
+			}
+			if m.Events[0].Slug == event.Slug {
+				m.Events[0].Volume = event.Volume
+			}
+			markets = append(markets, m)
+		}
 	}
 
 	// Sort by volume (highest first)
@@ -89,8 +134,41 @@ func (c *Client) GetRecentTrades(limit int) ([]types.Trade, error) {
 	return trades, nil
 }
 
-func (c *Client) GetLeaderboard(limit int) ([]types.LeaderboardEntry, error) {
+// GetMarketTrades returns the most recent trades across the given markets,
+// newest first.
+func (c *Client) GetMarketTrades(conditionIDs []string, limit int) ([]types.Trade, error) {
+	url := fmt.Sprintf("%s/trades?market=%s&limit=%d", c.cfg.DataAPIURL, strings.Join(conditionIDs, ","), limit)
+
+	var trades []types.Trade
+	if err := c.get(url, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// GetPriceHistory returns the price of a token between start and end sampled
+// every fidelity minutes.
+func (c *Client) GetPriceHistory(tokenID string, start, end time.Time, fidelity int) ([]types.PricePoint, error) {
+	url := fmt.Sprintf("%s/prices-history?market=%s&startTs=%d&endTs=%d&fidelity=%d",
+		c.cfg.ClobURL, tokenID, start.Unix(), end.Unix(), fidelity)
+
+	var history types.PriceHistory
+	if err := c.get(url, &history); err != nil {
+		return nil, err
+	}
+	return history.History, nil
+}
+
+// LeaderboardWindows are the periods the leaderboard can rank PnL over.
+var LeaderboardWindows = []string{"day", "week", "month", "all"}
+
+// GetLeaderboard returns the top traders by PnL over window, one of
+// LeaderboardWindows, or the API's default period when window is empty.
+func (c *Client) GetLeaderboard(window string, limit int) ([]types.LeaderboardEntry, error) {
 	url := fmt.Sprintf("%s/v1/leaderboard?limit=%d", c.cfg.DataAPIURL, limit)
+	if window != "" {
+		url += "&timePeriod=" + strings.ToUpper(window)
+	}
 
 	var entries []types.LeaderboardEntry
 	if err := c.get(url, &entries); err != nil {
@@ -110,15 +188,107 @@ func (c *Client) GetUserActivity(address string, limit int) ([]types.UserActivit
 }
 
 func (c *Client) get(url string, result interface{}) error {
-	resp, err := c.http.Get(url)
+	api, endpoint := c.labels(url)
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Tracer("api").Start(ctx, "GET "+api+" "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", http.MethodGet),
+			attribute.String("url.full", url),
+			attribute.String("polymarket.api", api),
+		))
+	defer span.End()
+
+	start := time.Now()
+	err := c.fetch(ctx, url, result)
+
+	elapsed := time.Since(start)
+	c.health.record(api, err)
+	metrics.APIRequestDuration.WithLabelValues(api, endpoint).Observe(elapsed.Seconds())
+	if err != nil {
+		metrics.APIErrors.WithLabelValues(api, endpoint).Inc()
+		logging.For("api").Debug("request failed", "api", api, "endpoint", endpoint, "duration", elapsed, "err", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	logging.For("api").Debug("request", "api", api, "endpoint", endpoint, "duration", elapsed)
+	return nil
+}
+
+func (c *Client) fetch(ctx context.Context, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("API error: %d", resp.StatusCode)
 	}
 
 	return json.NewDecoder(resp.Body).Decode(result)
 }
+
+// Health returns the request outcome of every API used so far, by name.
+func (c *Client) Health() map[string]Health {
+	c.health.mu.Lock()
+	defer c.health.mu.Unlock()
+
+	out := make(map[string]Health, len(c.health.apis))
+	for name, h := range c.health.apis {
+		out[name] = *h
+	}
+	return out
+}
+
+func (t *healthTracker) record(api string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.apis[api]
+	if !ok {
+		h = &Health{}
+		t.apis[api] = h
+	}
+	if err != nil {
+		h.LastFailure = time.Now()
+		h.LastError = err.Error()
+		h.ConsecutiveFailures++
+		return
+	}
+	h.LastSuccess = time.Now()
+	h.ConsecutiveFailures = 0
+}
+
+// labels names the API and endpoint of a request URL for metrics, dropping
+// IDs embedded in the path to keep label cardinality bounded.
+func (c *Client) labels(rawURL string) (string, string) {
+	api := "other"
+	base := ""
+	for name, b := range map[string]string{"gamma": c.cfg.GammaURL, "clob": c.cfg.ClobURL, "data": c.cfg.DataAPIURL} {
+		if strings.HasPrefix(rawURL, b) && len(b) > len(base) {
+			api, base = name, b
+		}
+	}
+
+	endpoint := strings.TrimPrefix(rawURL, base)
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	if strings.HasPrefix(endpoint, "/events/slug/") {
+		endpoint = "/events/slug"
+	}
+	return api, endpoint
+}
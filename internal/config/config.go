@@ -1,37 +1,233 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	GammaURL        string
-	ClobURL         string
-	ClobWsURL       string
-	DataAPIURL      string
-	MinTradeUSD     float64
-	MinLiquidityRatio float64
-	WebhookURL      string
-	SearchQueries   []string
-	PollIntervalMs  int
+	GammaURL            string
+	ClobURL             string
+	ClobWsURL           string
+	DataAPIURL          string
+	MinTradeUSD         float64
+	MinLiquidityRatio   float64
+	CriticalTradeUSD    float64
+	WebhookURL          string
+	WebhookFormat       string
+	WebhookTemplate     string
+	JSONWebhookURL      string
+	JSONWebhookSecret   string
+	JSONWebhookTemplate string
+	PagerDutyRoutingKey string
+	PagerDutyURL        string
+	OpsgenieAPIKey      string
+	OpsgenieURL         string
+	ResolveAfter        time.Duration
+	IncidentMinSeverity string
+	MQTTURL             string
+	MQTTUsername        string
+	MQTTPassword        string
+	MQTTPrefix          string
+	MQTTTrades          bool
+	NATSURL             string
+	NATSPrefix          string
+	NATSTrades          bool
+	ExecCommand         string
+	ExecTimeout         time.Duration
+	ExecConcurrency     int
+	ConsoleFormat       string
+	ConsoleTemplate     string
+	MarketCooldown      time.Duration
+	WalletCooldown      time.Duration
+	DedupeTTL           time.Duration
+	DetectionsMaxMB     int
+	PipelineWorkers     int
+	PipelineQueueSize   int
+	PipelineOverflow    string
+	HealthStaleAfter    time.Duration
+	HealthAlerts        bool
+	HealthSinks         []string
+	HTTPToken           string
+	ShutdownGrace       time.Duration
+	ReportDaily         string
+	ReportWeekly        string
+	ReportSinks         []string
+	ReportDir           string
+	ReportFormats       []string
+	LogLevel            string
+	LogFormat           string
+	TraceExporter       string
+	TraceEndpoint       string
+	TraceSampleRatio    float64
+	SearchQueries       []string
+	PollIntervalMs      int
 }
 
 func Load() *Config {
 	return &Config{
-		GammaURL:        "https://gamma-api.polymarket.com",
-		ClobURL:         "https://clob.polymarket.com",
-		ClobWsURL:       "wss://ws-subscriptions-clob.polymarket.com/ws/market",
-		DataAPIURL:      "https://data-api.polymarket.com",
-		MinTradeUSD:     getEnvFloat("MIN_TRADE_USD", 1000),
-		MinLiquidityRatio: getEnvFloat("MIN_LIQUIDITY_RATIO", 0.05),
-		WebhookURL:      os.Getenv("WEBHOOK_URL"),
-		SearchQueries:   getEnvSlice("SEARCH_QUERIES", []string{"trump", "russia", "china", "war", "election"}),
-		PollIntervalMs:  getEnvInt("POLL_INTERVAL_MS", 30000),
+		GammaURL:            "https://gamma-api.polymarket.com",
+		ClobURL:             "https://clob.polymarket.com",
+		ClobWsURL:           "wss://ws-subscriptions-clob.polymarket.com/ws/market",
+		DataAPIURL:          "https://data-api.polymarket.com",
+		MinTradeUSD:         getEnvFloat("MIN_TRADE_USD", 1000),
+		MinLiquidityRatio:   getEnvFloat("MIN_LIQUIDITY_RATIO", 0.05),
+		CriticalTradeUSD:    getEnvFloat("CRITICAL_TRADE_USD", 50000),
+		WebhookURL:          os.Getenv("WEBHOOK_URL"),
+		WebhookFormat:       os.Getenv("WEBHOOK_FORMAT"),
+		WebhookTemplate:     os.Getenv("WEBHOOK_TEMPLATE"),
+		JSONWebhookURL:      os.Getenv("JSON_WEBHOOK_URL"),
+		JSONWebhookSecret:   os.Getenv("JSON_WEBHOOK_SECRET"),
+		JSONWebhookTemplate: os.Getenv("JSON_WEBHOOK_TEMPLATE"),
+		PagerDutyRoutingKey: os.Getenv("PAGERDUTY_ROUTING_KEY"),
+		PagerDutyURL:        os.Getenv("PAGERDUTY_URL"),
+		OpsgenieAPIKey:      os.Getenv("OPSGENIE_API_KEY"),
+		OpsgenieURL:         os.Getenv("OPSGENIE_URL"),
+		ResolveAfter:        getEnvDuration("INCIDENT_RESOLVE_AFTER", 30*time.Minute),
+		IncidentMinSeverity: getEnv("INCIDENT_MIN_SEVERITY", "critical"),
+		MQTTURL:             os.Getenv("MQTT_URL"),
+		MQTTUsername:        os.Getenv("MQTT_USERNAME"),
+		MQTTPassword:        os.Getenv("MQTT_PASSWORD"),
+		MQTTPrefix:          os.Getenv("MQTT_PREFIX"),
+		MQTTTrades:          getEnvBool("MQTT_TRADES", false),
+		NATSURL:             os.Getenv("NATS_URL"),
+		NATSPrefix:          os.Getenv("NATS_PREFIX"),
+		NATSTrades:          getEnvBool("NATS_TRADES", false),
+		ExecCommand:         os.Getenv("EXEC_COMMAND"),
+		ExecTimeout:         getEnvDuration("EXEC_TIMEOUT", 10*time.Second),
+		ExecConcurrency:     getEnvInt("EXEC_CONCURRENCY", 4),
+		ConsoleFormat:       os.Getenv("CONSOLE_FORMAT"),
+		ConsoleTemplate:     os.Getenv("CONSOLE_TEMPLATE"),
+		MarketCooldown:      getEnvDuration("MARKET_COOLDOWN", 0),
+		WalletCooldown:      getEnvDuration("WALLET_COOLDOWN", 0),
+		PipelineWorkers:     getEnvInt("PIPELINE_WORKERS", 4),
+		PipelineQueueSize:   getEnvInt("PIPELINE_QUEUE_SIZE", 1024),
+		PipelineOverflow:    getEnv("PIPELINE_OVERFLOW", "drop-oldest"),
+		HealthStaleAfter:    getEnvDuration("HEALTH_STALE_AFTER", 5*time.Minute),
+		HealthAlerts:        getEnvBool("HEALTH_ALERTS", true),
+		HealthSinks:         getEnvSlice("HEALTH_SINKS", nil),
+		HTTPToken:           os.Getenv("HTTP_TOKEN"),
+		ShutdownGrace:       getEnvDuration("SHUTDOWN_GRACE", 30*time.Second),
+		DedupeTTL:           getEnvPositiveDuration("DEDUPE_TTL", 6*time.Hour),
+		DetectionsMaxMB:     getEnvInt("DETECTIONS_MAX_MB", 64),
+		ReportDaily:         os.Getenv("REPORT_DAILY"),
+		ReportWeekly:        os.Getenv("REPORT_WEEKLY"),
+		ReportSinks:         getEnvSlice("REPORT_SINKS", nil),
+		ReportDir:           os.Getenv("REPORT_DIR"),
+		ReportFormats:       getEnvSlice("REPORT_FORMATS", []string{"markdown"}),
+		LogLevel:            getEnv("LOG_LEVEL", "info"),
+		LogFormat:           getEnv("LOG_FORMAT", "text"),
+		TraceExporter:       getEnv("TRACE_EXPORTER", "none"),
+		TraceEndpoint:       os.Getenv("TRACE_ENDPOINT"),
+		TraceSampleRatio:    getEnvFloat("TRACE_SAMPLE_RATIO", 1),
+		SearchQueries:       getEnvSlice("SEARCH_QUERIES", []string{"trump", "russia", "china", "war", "election"}),
+		PollIntervalMs:      getEnvInt("POLL_INTERVAL_MS", 30000),
 	}
 }
 
+// envKinds lists the variables Load parses, by kind. A value that fails to
+// parse is silently replaced by its default, which InvalidEnv reports.
+var envKinds = map[string]string{
+	"MIN_TRADE_USD":          "number",
+	"MIN_LIQUIDITY_RATIO":    "number",
+	"CRITICAL_TRADE_USD":     "number",
+	"TRACE_SAMPLE_RATIO":     "number",
+	"EXEC_CONCURRENCY":       "integer",
+	"PIPELINE_WORKERS":       "integer",
+	"PIPELINE_QUEUE_SIZE":    "integer",
+	"POLL_INTERVAL_MS":       "integer",
+	"DETECTIONS_MAX_MB":      "integer",
+	"MQTT_TRADES":            "boolean",
+	"NATS_TRADES":            "boolean",
+	"HEALTH_ALERTS":          "boolean",
+	"INCIDENT_RESOLVE_AFTER": "duration",
+	"EXEC_TIMEOUT":           "duration",
+	"MARKET_COOLDOWN":        "duration",
+	"WALLET_COOLDOWN":        "duration",
+	"HEALTH_STALE_AFTER":     "duration",
+	"SHUTDOWN_GRACE":         "duration",
+	"DEDUPE_TTL":             "positive duration",
+}
+
+// InvalidEnv describes every set variable that Load could not parse and so
+// replaced with its default, sorted by name.
+func InvalidEnv() []string {
+	var invalid []string
+	for key, kind := range envKinds {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		var err error
+		switch kind {
+		case "number":
+			_, err = strconv.ParseFloat(v, 64)
+		case "integer":
+			_, err = strconv.Atoi(v)
+		case "boolean":
+			_, err = strconv.ParseBool(v)
+		case "duration":
+			_, err = time.ParseDuration(v)
+		case "positive duration":
+			var d time.Duration
+			if d, err = time.ParseDuration(v); err == nil && d <= 0 {
+				err = errors.New("not positive")
+			}
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s=%q is not a %s", key, v, kind))
+		}
+	}
+	sort.Strings(invalid)
+	return invalid
+}
+
+// LoadFile sets environment variables from a file of KEY=VALUE lines, so a
+// setup can be kept in one place instead of the shell. Blank lines, lines
+// starting with # and an "export " prefix are ignored, and values may be
+// quoted. Variables already set in the environment win over the file.
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: want KEY=VALUE", path, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
 func getEnvFloat(key string, def float64) float64 {
 	if v := os.Getenv(key); v != "" {
 		if f, err := strconv.ParseFloat(v, 64); err == nil {
@@ -50,6 +246,33 @@ func getEnvInt(key string, def int) int {
 	return def
 }
 
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+// getEnvPositiveDuration is getEnvDuration for settings where zero or less
+// would switch a safeguard off, so such values also fall back to def.
+func getEnvPositiveDuration(key string, def time.Duration) time.Duration {
+	if d := getEnvDuration(key, def); d > 0 {
+		return d
+	}
+	return def
+}
+
 func getEnvSlice(key string, def []string) []string {
 	if v := os.Getenv(key); v != "" {
 		return strings.Split(v, ",")
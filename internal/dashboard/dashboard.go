@@ -0,0 +1,612 @@
+// Package dashboard is the full-screen terminal UI of the dashboard command:
+// a scrolling feed of detections, the watched markets with their last price
+// and change over the past hour, WebSocket status and tracked whales' recent
+// trades. It is fed by the same detector and WebSocket client as start.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/mikefdy/polymarket-tool/internal/api"
+	"github.com/mikefdy/polymarket-tool/internal/detector"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+	"github.com/mikefdy/polymarket-tool/internal/ws"
+)
+
+// maxDetections and maxWhaleTrades bound the feed and the whale panel.
+const (
+	maxDetections  = 500
+	maxWhaleTrades = 200
+)
+
+const (
+	// rateWindow is the period the message rate is averaged over.
+	rateWindow = 10 * time.Second
+	// muteReloadInterval is how often mutes made elsewhere are picked up.
+	muteReloadInterval = 5 * time.Second
+	// statusTimeout is how long a message stays in the footer.
+	statusTimeout = 10 * time.Second
+	// priceHistoryLead is how much history before changeWindow is fetched
+	// so a price from exactly an hour ago is known.
+	priceHistoryLead = 10 * time.Minute
+)
+
+// muteChoices are the durations offered when muting from the dashboard;
+// zero mutes until unmuted.
+var muteChoices = []struct {
+	key rune
+	d   time.Duration
+}{
+	{'1', time.Hour},
+	{'2', 6 * time.Hour},
+	{'3', 24 * time.Hour},
+	{'4', 7 * 24 * time.Hour},
+	{'f', 0},
+}
+
+type panel int
+
+const (
+	feedPanel panel = iota
+	whalePanel
+)
+
+// detection is a feed entry. lookup reports the search for the trader of a
+// WebSocket trade: lookupPending while it runs, then empty or the error.
+type detection struct {
+	seq uint64
+	types.DetectedTrade
+	lookup string
+}
+
+const lookupPending = "looking up…"
+
+type rateSample struct {
+	at       time.Time
+	messages int64
+}
+
+// Dashboard renders the live state of a detector and WebSocket client. Feed
+// it with Watch, RecordTrade and RecordDetection, and show it with Run.
+type Dashboard struct {
+	api      *api.Client
+	detector *detector.Detector
+	ws       *ws.Client
+
+	mu          sync.Mutex
+	seq         uint64
+	detections  []*detection // newest first
+	whaleTrades []whaleTrade // newest first
+	whaleSeen   map[string]bool
+	prices      map[string]*marketPrice
+	mutes       []types.Mute
+
+	seeds chan *marketPrice
+	dirty chan struct{}
+	do    chan func()
+	ctx   context.Context
+
+	// UI state, only touched on Run's goroutine.
+	focus    panel
+	cursor   [2]uint64 // selected seq per panel; 0 follows the newest
+	details  bool      // the details box shows detailOf in detailIn
+	detailIn panel
+	detailOf uint64
+	muting   string // event slug awaiting a mute duration
+	status   string
+	statusOK bool
+	statusAt time.Time
+	rates    []rateSample
+}
+
+func New(apiClient *api.Client, detect *detector.Detector, wsClient *ws.Client) *Dashboard {
+	return &Dashboard{
+		api:       apiClient,
+		detector:  detect,
+		ws:        wsClient,
+		whaleSeen: make(map[string]bool),
+		prices:    make(map[string]*marketPrice),
+		seeds:     make(chan *marketPrice, 4096),
+		dirty:     make(chan struct{}, 1),
+		do:        make(chan func(), 16),
+		ctx:       context.Background(),
+	}
+}
+
+// Watch registers markets for the markets panel and queues a lookup of each
+// new one's price over the past hour.
+func (d *Dashboard) Watch(markets []types.Market) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range markets {
+		m := &markets[i]
+		if _, ok := d.prices[m.ConditionID]; ok || m.ConditionID == "" {
+			continue
+		}
+		mp := newMarketPrice(m)
+		d.prices[m.ConditionID] = mp
+		select {
+		case d.seeds <- mp:
+		default:
+		}
+	}
+}
+
+// RecordTrade updates the traded market's price.
+func (d *Dashboard) RecordTrade(t types.MarketTrade) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	mp, ok := d.prices[t.Market.ConditionID]
+	if !ok {
+		mp = newMarketPrice(t.Market)
+		d.prices[t.Market.ConditionID] = mp
+	}
+	mp.record(t.AssetID, t.Price, t.ReceivedAt)
+}
+
+// RecordDetection adds a detection to the top of the feed.
+func (d *Dashboard) RecordDetection(det types.DetectedTrade) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seq++
+	d.detections = append([]*detection{{seq: d.seq, DetectedTrade: det}}, d.detections...)
+	if len(d.detections) > maxDetections {
+		d.detections = d.detections[:maxDetections]
+	}
+	d.changed()
+}
+
+// changed asks Run to redraw. d.mu may be held.
+func (d *Dashboard) changed() {
+	select {
+	case d.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// onUI runs f on Run's goroutine, where UI state may be changed.
+func (d *Dashboard) onUI(f func()) {
+	select {
+	case d.do <- f:
+	case <-d.ctx.Done():
+	}
+}
+
+// Run takes over the terminal and shows the dashboard until the user quits
+// or ctx is done.
+func (d *Dashboard) Run(ctx context.Context) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+	return d.run(ctx, screen)
+}
+
+func (d *Dashboard) run(ctx context.Context, screen tcell.Screen) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.ctx = ctx
+
+	events := make(chan tcell.Event, 16)
+	quit := make(chan struct{})
+	defer close(quit)
+	go screen.ChannelEvents(events, quit)
+
+	go d.pollWhales(ctx)
+	go d.seedPrices(ctx)
+
+	d.reloadMutes()
+	d.sampleRate()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastMutes := time.Now()
+
+	for {
+		d.draw(screen)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			switch ev := ev.(type) {
+			case *tcell.EventKey:
+				if d.handleKey(ev) {
+					return nil
+				}
+			case *tcell.EventResize:
+				screen.Sync()
+			}
+		case f := <-d.do:
+			f()
+		case <-d.dirty:
+		case <-ticker.C:
+			d.sampleRate()
+			if time.Since(lastMutes) >= muteReloadInterval {
+				d.reloadMutes()
+				lastMutes = time.Now()
+			}
+		}
+	}
+}
+
+// seedPrices fetches the past hour of prices for markets queued by Watch.
+func (d *Dashboard) seedPrices(ctx context.Context) {
+	for {
+		var mp *marketPrice
+		select {
+		case <-ctx.Done():
+			return
+		case mp = <-d.seeds:
+		}
+		if len(mp.tokens) == 0 {
+			continue
+		}
+
+		now := time.Now()
+		history, err := d.api.WithContext(ctx).GetPriceHistory(mp.tokens[0], now.Add(-changeWindow-priceHistoryLead), now, 1)
+		if err != nil {
+			logging.For("dashboard").Warn("price history lookup failed", "conditionId", mp.market.ConditionID, "err", err)
+			continue
+		}
+		d.mu.Lock()
+		mp.seed(history)
+		d.mu.Unlock()
+	}
+}
+
+func (d *Dashboard) reloadMutes() {
+	mutes, err := storage.LoadMutes()
+	if err != nil {
+		logging.For("dashboard").Warn("failed to reload mutes", "err", err)
+		return
+	}
+	d.mu.Lock()
+	d.mutes = mutes
+	d.mu.Unlock()
+}
+
+// muted reports whether an active mute covers det. d.mu must be held.
+func (d *Dashboard) muted(det types.DetectedTrade) bool {
+	now := time.Now()
+	for _, m := range d.mutes {
+		if !m.Expired(now) && m.Covers(det) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Dashboard) sampleRate() {
+	now := time.Now()
+	d.rates = append(d.rates, rateSample{at: now, messages: d.ws.Status().Messages})
+	for len(d.rates) > 2 && now.Sub(d.rates[1].at) >= rateWindow {
+		d.rates = d.rates[1:]
+	}
+}
+
+// messageRate is WebSocket frames per second over rateWindow.
+func (d *Dashboard) messageRate() float64 {
+	if len(d.rates) < 2 {
+		return 0
+	}
+	first, last := d.rates[0], d.rates[len(d.rates)-1]
+	secs := last.at.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	return float64(last.messages-first.messages) / secs
+}
+
+func (d *Dashboard) setStatus(ok bool, format string, args ...interface{}) {
+	d.status = fmt.Sprintf(format, args...)
+	d.statusOK = ok
+	d.statusAt = time.Now()
+}
+
+// ============= KEYS =============
+
+// handleKey acts on a key press and reports whether to quit.
+func (d *Dashboard) handleKey(ev *tcell.EventKey) bool {
+	if ev.Key() == tcell.KeyCtrlC {
+		return true
+	}
+
+	if d.muting != "" {
+		d.handleMuteKey(ev)
+		return false
+	}
+
+	if d.details {
+		switch {
+		case ev.Key() == tcell.KeyEscape, ev.Key() == tcell.KeyEnter, ev.Rune() == 'q':
+			d.details = false
+		case ev.Rune() == 'm':
+			d.startMute()
+		case ev.Rune() == 'w':
+			d.addSelectedWhale()
+		}
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab:
+		d.focus = 1 - d.focus
+	case tcell.KeyUp:
+		d.move(-1)
+	case tcell.KeyDown:
+		d.move(1)
+	case tcell.KeyPgUp:
+		d.move(-10)
+	case tcell.KeyPgDn:
+		d.move(10)
+	case tcell.KeyHome:
+		d.cursor[d.focus] = 0
+	case tcell.KeyEnd:
+		d.move(maxDetections)
+	case tcell.KeyEnter:
+		d.openDetails()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			d.move(-1)
+		case 'j':
+			d.move(1)
+		case 'g':
+			d.cursor[d.focus] = 0
+		case 'G':
+			d.move(maxDetections)
+		case 'm':
+			d.startMute()
+		case 'w':
+			d.addSelectedWhale()
+		}
+	}
+	return false
+}
+
+// seqs lists the entries of the focused panel, newest first.
+func (d *Dashboard) seqs(p panel) []uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var seqs []uint64
+	if p == feedPanel {
+		for _, det := range d.detections {
+			seqs = append(seqs, det.seq)
+		}
+	} else {
+		for _, t := range d.whaleTrades {
+			seqs = append(seqs, t.seq)
+		}
+	}
+	return seqs
+}
+
+// selectedIndex finds the cursor of panel p in seqs. A cursor on an entry
+// that has scrolled out of the list falls back to the newest.
+func selectedIndex(seqs []uint64, cursor uint64) int {
+	for i, s := range seqs {
+		if s == cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+// move shifts the focused panel's selection by delta entries. Back at the
+// top, the selection follows new entries again.
+func (d *Dashboard) move(delta int) {
+	seqs := d.seqs(d.focus)
+	if len(seqs) == 0 {
+		return
+	}
+	i := selectedIndex(seqs, d.cursor[d.focus]) + delta
+	if i >= len(seqs) {
+		i = len(seqs) - 1
+	}
+	if i <= 0 {
+		d.cursor[d.focus] = 0
+		return
+	}
+	d.cursor[d.focus] = seqs[i]
+}
+
+// selected returns the entry under the cursor of panel p, or the one shown
+// in the details box while it is open.
+func (d *Dashboard) selected(p panel) (*detection, *whaleTrade) {
+	cursor := d.cursor[p]
+	if d.details && d.detailIn == p {
+		cursor = d.detailOf
+	}
+	seqs := d.seqs(p)
+	if len(seqs) == 0 {
+		return nil, nil
+	}
+	seq := seqs[selectedIndex(seqs, cursor)]
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p == feedPanel {
+		for _, det := range d.detections {
+			if det.seq == seq {
+				return det, nil
+			}
+		}
+		return nil, nil
+	}
+	for i := range d.whaleTrades {
+		if d.whaleTrades[i].seq == seq {
+			t := d.whaleTrades[i]
+			return nil, &t
+		}
+	}
+	return nil, nil
+}
+
+func (d *Dashboard) openDetails() {
+	det, trade := d.selected(d.focus)
+	switch {
+	case det != nil:
+		d.detailOf = det.seq
+		d.resolveTrader(det, nil)
+	case trade != nil:
+		d.detailOf = trade.seq
+	default:
+		return
+	}
+	d.details, d.detailIn = true, d.focus
+}
+
+// resolveTrader fills in the wallet and name behind a WebSocket detection in
+// the background, then calls then on the UI goroutine if it is set.
+func (d *Dashboard) resolveTrader(det *detection, then func(wallet, name string)) {
+	d.mu.Lock()
+	wallet, name, pending := det.Wallet, det.Trader, det.lookup == lookupPending
+	if wallet == "" && !pending {
+		det.lookup = lookupPending
+	}
+	snapshot := det.DetectedTrade
+	d.mu.Unlock()
+
+	if wallet != "" {
+		if then != nil {
+			then(wallet, name)
+		}
+		return
+	}
+	if pending && then == nil {
+		return
+	}
+
+	go func() {
+		t, err := d.findTrader(d.ctx, snapshot)
+
+		d.mu.Lock()
+		if err != nil {
+			det.lookup = err.Error()
+		} else {
+			det.Wallet = t.ProxyWallet
+			det.Trader = traderName(t.Name, t.Pseudonym, t.ProxyWallet)
+			det.lookup = ""
+		}
+		d.mu.Unlock()
+
+		d.onUI(func() {
+			if err != nil {
+				d.setStatus(false, "Trader lookup failed: %v", err)
+				return
+			}
+			if then != nil {
+				then(t.ProxyWallet, traderName(t.Name, t.Pseudonym, ""))
+			}
+		})
+	}()
+}
+
+// startMute asks how long to mute the selected entry's market.
+func (d *Dashboard) startMute() {
+	det, trade := d.selected(d.focus)
+	switch {
+	case det != nil && det.Market != nil:
+		d.muting = det.Market.EventSlug()
+	case trade != nil && trade.EventSlug != "":
+		d.muting = trade.EventSlug
+	case trade != nil:
+		d.muting = trade.Slug
+	}
+}
+
+func (d *Dashboard) handleMuteKey(ev *tcell.EventKey) {
+	target := d.muting
+	if ev.Key() == tcell.KeyEscape {
+		d.muting = ""
+		return
+	}
+	for _, c := range muteChoices {
+		if ev.Rune() != c.key {
+			continue
+		}
+		d.muting = ""
+
+		now := time.Now()
+		mute := types.Mute{
+			Kind:    types.MuteMarket,
+			Target:  target,
+			AddedAt: now.Format(time.RFC3339),
+		}
+		if c.d > 0 {
+			mute.Until = now.Add(c.d).Format(time.RFC3339)
+		}
+		if err := storage.AddMute(mute); err != nil {
+			d.setStatus(false, "Mute failed: %v", err)
+			return
+		}
+		d.reloadMutes()
+		if mute.Until != "" {
+			d.setStatus(true, "✓ Muted %s until %s", target, now.Add(c.d).Format("Jan 02 15:04"))
+		} else {
+			d.setStatus(true, "✓ Muted %s", target)
+		}
+		logging.For("dashboard").Info("market muted", "slug", target, "until", mute.Until)
+		return
+	}
+}
+
+// addSelectedWhale tracks the trader of the selected detection, looking up
+// the wallet first for WebSocket trades.
+func (d *Dashboard) addSelectedWhale() {
+	det, trade := d.selected(d.focus)
+	switch {
+	case trade != nil:
+		d.setStatus(false, "%s is already tracked", trade.whale)
+	case det != nil:
+		d.setStatus(true, "Looking up the trader…")
+		d.resolveTrader(det, d.addWhale)
+	}
+}
+
+func (d *Dashboard) addWhale(wallet, name string) {
+	if name == "" || strings.HasSuffix(name, "…") {
+		name = wallet[:10]
+	}
+	whale := types.Whale{
+		Address: wallet,
+		Name:    name,
+		AddedAt: time.Now().Format(time.RFC3339),
+	}
+	added, err := storage.AddWhale(whale)
+	if err != nil {
+		d.setStatus(false, "Adding whale failed: %v", err)
+		return
+	}
+	if !added {
+		d.setStatus(false, "%s is already tracked", name)
+		return
+	}
+
+	whales, err := storage.LoadWhales()
+	if err == nil {
+		d.detector.SetWhales(whales)
+	}
+	d.setStatus(true, "✓ Added whale %s (%s)", name, wallet)
+	logging.For("dashboard").Info("whale added", "address", wallet, "name", name)
+	go d.fetchWhale(d.ctx, whale)
+}
@@ -0,0 +1,425 @@
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// minWidth and minHeight are the smallest terminal the layout fits in.
+const (
+	minWidth  = 60
+	minHeight = 15
+)
+
+var (
+	styleDefault  = tcell.StyleDefault
+	styleHeader   = tcell.StyleDefault.Reverse(true)
+	styleDim      = tcell.StyleDefault.Dim(true)
+	styleBold     = tcell.StyleDefault.Bold(true)
+	styleBorder   = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	styleFocused  = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	styleSelected = tcell.StyleDefault.Reverse(true)
+	styleUp       = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	styleDown     = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	styleOK       = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	styleError    = tcell.StyleDefault.Foreground(tcell.ColorRed)
+)
+
+var severityStyles = map[string]tcell.Style{
+	types.SeverityCritical: tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true),
+	types.SeverityWarning:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+}
+
+func (d *Dashboard) draw(screen tcell.Screen) {
+	screen.Clear()
+	w, h := screen.Size()
+	if w < minWidth || h < minHeight {
+		drawText(screen, 0, 0, w, styleDefault, fmt.Sprintf("Terminal too small (need %dx%d)", minWidth, minHeight))
+		screen.Show()
+		return
+	}
+
+	d.drawHeader(screen, w)
+
+	bodyH := h - 2
+	leftW := w * 3 / 5
+	rightW := w - leftW
+	marketsH := bodyH * 3 / 5
+
+	d.drawFeed(screen, 0, 1, leftW, bodyH)
+	d.drawMarkets(screen, leftW, 1, rightW, marketsH)
+	d.drawWhales(screen, leftW, 1+marketsH, rightW, bodyH-marketsH)
+	d.drawFooter(screen, h-1, w)
+
+	if d.details {
+		d.drawDetails(screen, w, h)
+	}
+	screen.Show()
+}
+
+func (d *Dashboard) drawHeader(screen tcell.Screen, w int) {
+	fill(screen, 0, 0, w, 1, styleHeader)
+	x := drawText(screen, 1, 0, w-1, styleHeader.Bold(true), "Polymarket dashboard")
+
+	st := d.ws.Status()
+	wsText, wsStyle := "● connected", styleHeader.Foreground(tcell.ColorGreen)
+	switch {
+	case st.GaveUp:
+		wsText, wsStyle = "✕ gave up reconnecting", styleHeader.Foreground(tcell.ColorRed)
+	case !st.Connected:
+		wsText, wsStyle = "○ disconnected", styleHeader.Foreground(tcell.ColorRed)
+	}
+
+	d.mu.Lock()
+	detections := len(d.detections)
+	d.mu.Unlock()
+
+	x += 1 + drawText(screen, x+1, 0, w-x-1, styleHeader, " │ WS ")
+	x += drawText(screen, x, 0, w-x, wsStyle, wsText)
+	parts := []string{
+		fmt.Sprintf("%.1f msg/s", d.messageRate()),
+		fmt.Sprintf("%d assets", st.SubscribedAssets),
+		fmt.Sprintf("%d reconnects", st.Reconnects),
+		fmt.Sprintf("%d detections", detections),
+	}
+	drawText(screen, x, 0, w-x, styleHeader, " │ "+strings.Join(parts, " │ "))
+
+	clock := time.Now().Format("15:04:05")
+	drawText(screen, w-len(clock)-1, 0, len(clock), styleHeader, clock)
+}
+
+func (d *Dashboard) drawFooter(screen tcell.Screen, y, w int) {
+	switch {
+	case d.muting != "":
+		drawText(screen, 1, y, w-1, styleBold, "Mute "+d.muting+" for:  1 = 1h   2 = 6h   3 = 1d   4 = 7d   f = until unmuted   Esc = cancel")
+	case d.status != "" && time.Since(d.statusAt) < statusTimeout:
+		style := styleOK
+		if !d.statusOK {
+			style = styleError
+		}
+		drawText(screen, 1, y, w-1, style, d.status)
+	case d.details:
+		drawText(screen, 1, y, w-1, styleDim, "m mute market   w add trader as whale   Esc close")
+	default:
+		drawText(screen, 1, y, w-1, styleDim, "↑↓ select   Tab switch panel   Enter details   m mute market   w add trader as whale   q quit")
+	}
+}
+
+func (d *Dashboard) drawFeed(screen tcell.Screen, x, y, w, h int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	drawBox(screen, x, y, w, h, fmt.Sprintf("Detections (%d)", len(d.detections)), d.focus == feedPanel)
+	innerW, rows := w-2, h-2
+	if len(d.detections) == 0 {
+		drawText(screen, x+2, y+1, innerW-1, styleDim, "Waiting for detections…")
+		return
+	}
+
+	sel := 0
+	for i, det := range d.detections {
+		if det.seq == d.cursor[feedPanel] {
+			sel = i
+		}
+	}
+	offset := scrollOffset(sel, rows)
+
+	for row := 0; row < rows && offset+row < len(d.detections); row++ {
+		det := d.detections[offset+row]
+		style := severityStyles[det.Severity]
+		if d.muted(det.DetectedTrade) {
+			style = styleDim
+		}
+		if offset+row == sel && d.focus == feedPanel {
+			style = style.Reverse(true)
+		}
+
+		_, outcome := d.detector.MarketForAsset(det.AssetID)
+		who := det.WhaleName
+		if who != "" {
+			who = "🐋 " + who
+		} else if d.muted(det.DetectedTrade) {
+			who = "muted"
+		}
+		line := fmt.Sprintf("%s %-4s %8s  %-10s %s",
+			det.DetectedAt.Format("15:04:05"),
+			strings.ToUpper(det.Side),
+			formatUSD(det.UsdValue),
+			runewidth.Truncate(fmt.Sprintf("%s %s", outcome, formatCents(det.Price)), 10, "…"),
+			det.Market.Question)
+		fill(screen, x+1, y+1+row, innerW, 1, style)
+		used := drawText(screen, x+1, y+1+row, innerW, style, line)
+		if who != "" && used < innerW {
+			label := " " + who
+			lw := runewidth.StringWidth(label)
+			if used+lw > innerW {
+				label = runewidth.Truncate(label, innerW-used, "…")
+				lw = runewidth.StringWidth(label)
+			}
+			drawText(screen, x+1+innerW-lw, y+1+row, lw, style.Bold(true), label)
+		}
+	}
+}
+
+func (d *Dashboard) drawMarkets(screen tcell.Screen, x, y, w, h int) {
+	stats := d.detector.Stats()
+	drawBox(screen, x, y, w, h, fmt.Sprintf("Watched markets (%d)", len(stats)), false)
+	innerW, rows := w-2, h-2
+	if rows < 2 {
+		return
+	}
+
+	// Price, 1h change, session volume and detections, right-aligned.
+	const colsW = 10 + 8 + 9 + 4
+	questionW := innerW - colsW - 1
+	header := runewidth.FillRight("Market", questionW) + fmt.Sprintf(" %10s%8s%9s%4s", "Price", "1h", "Volume", "Det")
+	drawText(screen, x+1, y+1, innerW, styleDim, header)
+
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for row, s := range stats {
+		if row >= rows-1 {
+			break
+		}
+		ry := y + 2 + row
+		drawText(screen, x+1, ry, questionW, styleDefault, s.Question)
+
+		cx := x + 1 + questionW + 1
+		price, change := "–", ""
+		changeStyle := styleDim
+		if mp, ok := d.prices[s.ConditionID]; ok {
+			if last, ok := mp.last(); ok {
+				price = runewidth.Truncate(mp.outcome, 5, "…") + " " + formatCents(last)
+			}
+			if delta, exact, ok := mp.change(now); ok {
+				change = fmt.Sprintf("%+.1f¢", delta*100)
+				if !exact {
+					change = "~" + change
+				}
+				switch {
+				case delta > 0.0005:
+					changeStyle = styleUp
+				case delta < -0.0005:
+					changeStyle = styleDown
+				}
+			}
+		}
+		drawText(screen, cx, ry, 10, styleDefault, padLeft(price, 10))
+		drawText(screen, cx+10, ry, 8, changeStyle, padLeft(change, 8))
+		drawText(screen, cx+18, ry, 9, styleDefault, padLeft(formatUSD(s.VolumeUSD), 9))
+		detStyle := styleDim
+		if s.Detections > 0 {
+			detStyle = styleBold
+		}
+		drawText(screen, cx+27, ry, 4, detStyle, padLeft(fmt.Sprint(s.Detections), 4))
+	}
+}
+
+func (d *Dashboard) drawWhales(screen tcell.Screen, x, y, w, h int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	drawBox(screen, x, y, w, h, "Whale activity", d.focus == whalePanel)
+	innerW, rows := w-2, h-2
+	if len(d.whaleTrades) == 0 {
+		drawText(screen, x+2, y+1, innerW-1, styleDim, "No recent whale trades")
+		return
+	}
+
+	sel := 0
+	for i, t := range d.whaleTrades {
+		if t.seq == d.cursor[whalePanel] {
+			sel = i
+		}
+	}
+	offset := scrollOffset(sel, rows)
+
+	for row := 0; row < rows && offset+row < len(d.whaleTrades); row++ {
+		t := d.whaleTrades[offset+row]
+		style := styleDefault
+		if offset+row == sel && d.focus == whalePanel {
+			style = styleSelected
+		}
+		line := fmt.Sprintf("%-6s %-12s %-4s %8s  %s",
+			shortTime(time.Unix(t.Timestamp, 0)),
+			runewidth.Truncate(t.whale, 12, "…"),
+			strings.ToUpper(t.Side),
+			formatUSD(t.UsdcSize),
+			t.Title)
+		fill(screen, x+1, y+1+row, innerW, 1, style)
+		drawText(screen, x+1, y+1+row, innerW, style, line)
+	}
+}
+
+func (d *Dashboard) drawDetails(screen tcell.Screen, w, h int) {
+	lines := d.detailLines()
+	if lines == nil {
+		d.details = false
+		return
+	}
+
+	bw := w - 8
+	if bw > 100 {
+		bw = 100
+	}
+	bh := len(lines) + 2
+	if bh > h-2 {
+		bh = h - 2
+	}
+	bx, by := (w-bw)/2, (h-bh)/2
+
+	fill(screen, bx, by, bw, bh, styleDefault)
+	drawBox(screen, bx, by, bw, bh, "Details", true)
+	for i, l := range lines {
+		if i >= bh-2 {
+			break
+		}
+		drawText(screen, bx+2, by+1+i, bw-4, l.style, l.text)
+	}
+}
+
+type detailLine struct {
+	text  string
+	style tcell.Style
+}
+
+// detailLines describes the entry the details box is open on, or nil once it
+// has scrolled out of the panel.
+func (d *Dashboard) detailLines() []detailLine {
+	det, trade := d.selected(d.detailIn)
+
+	var lines []detailLine
+	add := func(label, value string) {
+		lines = append(lines, detailLine{text: fmt.Sprintf("%-10s %s", label, value)})
+	}
+
+	switch {
+	case det != nil:
+		d.mu.Lock()
+		t, lookup, muted := det.DetectedTrade, det.lookup, d.muted(det.DetectedTrade)
+		d.mu.Unlock()
+
+		_, outcome := d.detector.MarketForAsset(t.AssetID)
+		lines = append(lines, detailLine{text: t.Market.Question, style: styleBold})
+		add("Event", "https://polymarket.com/event/"+t.Market.EventSlug())
+		add("Trade", fmt.Sprintf("%s %s at %s × %.2f = %s", strings.ToUpper(t.Side), outcome, formatCents(t.Price), t.Size, formatUSD(t.UsdValue)))
+		add("Severity", t.Severity)
+		for i, r := range t.Reasons {
+			label := ""
+			if i == 0 {
+				label = "Reasons"
+			}
+			add(label, r)
+		}
+		add("Detected", t.DetectedAt.Format("2006-01-02 15:04:05"))
+		switch {
+		case t.Wallet != "" && t.Trader != "":
+			add("Trader", fmt.Sprintf("%s (%s)", t.Trader, t.Wallet))
+		case t.Wallet != "":
+			add("Trader", t.Wallet)
+		case lookup != "":
+			add("Trader", lookup)
+		}
+		if muted {
+			add("Muted", "yes")
+		}
+	case trade != nil:
+		lines = append(lines, detailLine{text: trade.Title, style: styleBold})
+		add("Event", "https://polymarket.com/event/"+trade.EventSlug)
+		add("Whale", fmt.Sprintf("%s (%s)", trade.whale, trade.ProxyWallet))
+		add("Trade", fmt.Sprintf("%s %s at %s × %.2f = %s", strings.ToUpper(trade.Side), trade.Outcome, formatCents(trade.Price), trade.Size, formatUSD(trade.UsdcSize)))
+		add("Time", time.Unix(trade.Timestamp, 0).Format("2006-01-02 15:04:05"))
+		add("Tx", trade.TransactionHash)
+	default:
+		return nil
+	}
+	return lines
+}
+
+// ============= HELPERS =============
+
+// drawText writes text from x, cut to width with an ellipsis, and returns
+// the columns used.
+func drawText(screen tcell.Screen, x, y, width int, style tcell.Style, text string) int {
+	if width <= 0 {
+		return 0
+	}
+	text = runewidth.Truncate(text, width, "…")
+	col := 0
+	for _, r := range text {
+		screen.SetContent(x+col, y, r, nil, style)
+		col += runewidth.RuneWidth(r)
+	}
+	return col
+}
+
+func fill(screen tcell.Screen, x, y, w, h int, style tcell.Style) {
+	for row := y; row < y+h; row++ {
+		for col := x; col < x+w; col++ {
+			screen.SetContent(col, row, ' ', nil, style)
+		}
+	}
+}
+
+// drawBox draws a border with title; the focused panel's is highlighted.
+func drawBox(screen tcell.Screen, x, y, w, h int, title string, focused bool) {
+	style := styleBorder
+	if focused {
+		style = styleFocused
+	}
+	for col := x + 1; col < x+w-1; col++ {
+		screen.SetContent(col, y, tcell.RuneHLine, nil, style)
+		screen.SetContent(col, y+h-1, tcell.RuneHLine, nil, style)
+	}
+	for row := y + 1; row < y+h-1; row++ {
+		screen.SetContent(x, row, tcell.RuneVLine, nil, style)
+		screen.SetContent(x+w-1, row, tcell.RuneVLine, nil, style)
+	}
+	screen.SetContent(x, y, tcell.RuneULCorner, nil, style)
+	screen.SetContent(x+w-1, y, tcell.RuneURCorner, nil, style)
+	screen.SetContent(x, y+h-1, tcell.RuneLLCorner, nil, style)
+	screen.SetContent(x+w-1, y+h-1, tcell.RuneLRCorner, nil, style)
+	drawText(screen, x+2, y, w-4, style, " "+title+" ")
+}
+
+// scrollOffset is the first row shown so that selected stays in view.
+func scrollOffset(selected, rows int) int {
+	if selected < rows {
+		return 0
+	}
+	return selected - rows + 1
+}
+
+func padLeft(s string, width int) string {
+	return runewidth.FillLeft(runewidth.Truncate(s, width, "…"), width)
+}
+
+// shortTime shows today's times as 15:04 and older ones as the date.
+func shortTime(t time.Time) string {
+	if time.Since(t) < 24*time.Hour {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan 02")
+}
+
+func formatCents(price float64) string {
+	return fmt.Sprintf("%.1f¢", price*100)
+}
+
+func formatUSD(value float64) string {
+	if value >= 1_000_000 {
+		return fmt.Sprintf("$%.2fM", value/1_000_000)
+	}
+	if value >= 1_000 {
+		return fmt.Sprintf("$%.1fK", value/1_000)
+	}
+	return fmt.Sprintf("$%.0f", value)
+}
@@ -0,0 +1,104 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// changeWindow is how far back the markets panel compares prices.
+const changeWindow = time.Hour
+
+// pricePoint is a market's first-outcome price at a moment.
+type pricePoint struct {
+	at    time.Time
+	price float64
+}
+
+// marketPrice follows the price of a market's first outcome, usually "Yes",
+// long enough to report its change over changeWindow.
+type marketPrice struct {
+	market  *types.Market
+	tokens  []string
+	outcome string
+	points  []pricePoint // oldest first
+}
+
+func newMarketPrice(m *types.Market) *marketPrice {
+	mp := &marketPrice{market: m}
+	json.Unmarshal([]byte(m.ClobTokens), &mp.tokens)
+
+	var outcomes []string
+	json.Unmarshal([]byte(m.Outcomes), &outcomes)
+	if len(outcomes) > 0 {
+		mp.outcome = outcomes[0]
+	}
+	return mp
+}
+
+// record adds a trade on assetID. Trades on the second outcome of a binary
+// market count at the complementary price; others are ignored.
+func (mp *marketPrice) record(assetID string, price float64, at time.Time) {
+	switch {
+	case len(mp.tokens) > 0 && assetID == mp.tokens[0]:
+	case len(mp.tokens) == 2 && assetID == mp.tokens[1]:
+		price = 1 - price
+	default:
+		return
+	}
+	mp.add(pricePoint{at: at, price: price})
+}
+
+// add inserts p in time order and forgets points no longer needed to know
+// the price changeWindow ago.
+func (mp *marketPrice) add(p pricePoint) {
+	i := len(mp.points)
+	for i > 0 && mp.points[i-1].at.After(p.at) {
+		i--
+	}
+	mp.points = append(mp.points, pricePoint{})
+	copy(mp.points[i+1:], mp.points[i:])
+	mp.points[i] = p
+
+	cutoff := time.Now().Add(-changeWindow)
+	drop := 0
+	for drop+1 < len(mp.points) && !mp.points[drop+1].at.After(cutoff) {
+		drop++
+	}
+	mp.points = mp.points[drop:]
+}
+
+// last returns the latest known price.
+func (mp *marketPrice) last() (float64, bool) {
+	if len(mp.points) == 0 {
+		return 0, false
+	}
+	return mp.points[len(mp.points)-1].price, true
+}
+
+// change returns the move since changeWindow ago, measured from the last
+// price at or before then. Without one it is measured from the oldest price
+// seen, and exact is false.
+func (mp *marketPrice) change(now time.Time) (delta float64, exact, ok bool) {
+	if len(mp.points) == 0 {
+		return 0, false, false
+	}
+	cutoff := now.Add(-changeWindow)
+	base := mp.points[0]
+	for _, p := range mp.points[1:] {
+		if p.at.After(cutoff) {
+			break
+		}
+		base = p
+	}
+	last := mp.points[len(mp.points)-1]
+	return last.price - base.price, !base.at.After(cutoff), true
+}
+
+// seed adds price history fetched for the first outcome.
+func (mp *marketPrice) seed(history []types.PricePoint) {
+	for _, h := range history {
+		mp.add(pricePoint{at: time.Unix(h.T, 0), price: h.P})
+	}
+}
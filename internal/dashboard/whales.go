@@ -0,0 +1,168 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	// whalePollInterval is how often tracked whales' activity is fetched.
+	whalePollInterval = time.Minute
+	// whaleActivityLimit is how many activity entries are fetched per whale.
+	whaleActivityLimit = 10
+	// traderLookupLimit is how many of a market's recent trades are searched
+	// for the one behind a detection.
+	traderLookupLimit = 200
+)
+
+// whaleTrade is a tracked whale's trade for the whale panel.
+type whaleTrade struct {
+	seq   uint64
+	whale string
+	types.UserActivity
+}
+
+// pollWhales fetches every tracked whale's recent trades now and then every
+// whalePollInterval, until ctx is done.
+func (d *Dashboard) pollWhales(ctx context.Context) {
+	ticker := time.NewTicker(whalePollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.fetchWhaleTrades(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dashboard) fetchWhaleTrades(ctx context.Context) {
+	whales, err := storage.LoadWhales()
+	if err != nil {
+		logging.For("dashboard").Warn("failed to load whales", "err", err)
+		return
+	}
+
+	for _, w := range whales {
+		if ctx.Err() != nil {
+			return
+		}
+		d.fetchWhale(ctx, w)
+	}
+}
+
+func (d *Dashboard) fetchWhale(ctx context.Context, w types.Whale) {
+	activity, err := d.api.WithContext(ctx).GetUserActivity(w.Address, whaleActivityLimit)
+	if err != nil {
+		logging.For("dashboard").Warn("whale activity lookup failed", "whale", w.Name, "err", err)
+		return
+	}
+	var trades []types.UserActivity
+	for _, a := range activity {
+		if a.Type == "TRADE" {
+			trades = append(trades, a)
+		}
+	}
+	d.addWhaleTrades(w.Name, trades)
+}
+
+// addWhaleTrades merges trades into the whale panel, newest first, skipping
+// ones already shown.
+func (d *Dashboard) addWhaleTrades(whale string, trades []types.UserActivity) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	added := false
+	for _, t := range trades {
+		key := t.TransactionHash + "/" + t.Asset
+		if d.whaleSeen[key] {
+			continue
+		}
+		d.whaleSeen[key] = true
+		d.seq++
+		d.whaleTrades = append(d.whaleTrades, whaleTrade{seq: d.seq, whale: whale, UserActivity: t})
+		added = true
+	}
+	if !added {
+		return
+	}
+
+	sort.SliceStable(d.whaleTrades, func(i, j int) bool {
+		return d.whaleTrades[i].Timestamp > d.whaleTrades[j].Timestamp
+	})
+	if len(d.whaleTrades) > maxWhaleTrades {
+		for _, t := range d.whaleTrades[maxWhaleTrades:] {
+			delete(d.whaleSeen, t.TransactionHash+"/"+t.Asset)
+		}
+		d.whaleTrades = d.whaleTrades[:maxWhaleTrades]
+	}
+	d.changed()
+}
+
+// findTrader looks up who made a detected trade. WebSocket trades arrive
+// without a wallet, so it searches the market's recent trades for the one on
+// the same asset with the closest size, price and time.
+func (d *Dashboard) findTrader(ctx context.Context, det types.DetectedTrade) (types.Trade, error) {
+	trades, err := d.api.WithContext(ctx).GetMarketTrades([]string{det.Market.ConditionID}, traderLookupLimit)
+	if err != nil {
+		return types.Trade{}, err
+	}
+
+	at := det.DetectedAt
+	if ms, err := strconv.ParseInt(det.Timestamp, 10, 64); err == nil {
+		at = time.UnixMilli(ms)
+	}
+
+	var (
+		best  types.Trade
+		found bool
+		gap   time.Duration
+	)
+	for _, t := range trades {
+		if t.Asset != det.AssetID || t.ProxyWallet == "" {
+			continue
+		}
+		if math.Abs(t.Size-det.Size) > 0.01*det.Size+0.01 || math.Abs(t.Price-det.Price) > 0.005 {
+			continue
+		}
+		if det.Side != "" && !strings.EqualFold(t.Side, det.Side) {
+			continue
+		}
+		g := at.Sub(time.Unix(t.Timestamp, 0)).Abs()
+		if g > 5*time.Minute {
+			continue
+		}
+		if !found || g < gap {
+			best, gap, found = t, g, true
+		}
+	}
+	if !found {
+		return types.Trade{}, fmt.Errorf("trade not found among the market's last %d trades", len(trades))
+	}
+	return best, nil
+}
+
+// traderName is how a trade's maker is shown: their name, pseudonym or
+// shortened wallet.
+func traderName(name, pseudonym, wallet string) string {
+	switch {
+	case name != "":
+		return name
+	case pseudonym != "":
+		return pseudonym
+	case len(wallet) > 10:
+		return wallet[:10] + "…"
+	}
+	return wallet
+}
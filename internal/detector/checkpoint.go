@@ -0,0 +1,67 @@
+package detector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// Checkpoint captures the state worth keeping across a restart: the trade
+// dedupe set, statistics for watched markets and cached order book
+// liquidity.
+func (d *Detector) Checkpoint() *types.DetectorState {
+	state := &types.DetectorState{
+		SavedAt: time.Now().UTC(),
+		Seen:    d.seenTxHashes.snapshot(),
+	}
+
+	watched := d.GetWatchedConditionIDs()
+	d.statsMu.RLock()
+	state.Stats = make([]types.MarketStats, 0, len(d.stats))
+	for id, s := range d.stats {
+		if watched[id] {
+			state.Stats = append(state.Stats, *s)
+		}
+	}
+	d.statsMu.RUnlock()
+	sort.Slice(state.Stats, func(i, j int) bool {
+		return state.Stats[i].ConditionID < state.Stats[j].ConditionID
+	})
+
+	d.cacheMu.RLock()
+	state.Liquidity = make([]types.LiquiditySnapshot, 0, len(d.liquidityCache))
+	for id, e := range d.liquidityCache {
+		state.Liquidity = append(state.Liquidity, types.LiquiditySnapshot{AssetID: id, USD: e.value, FetchedAt: e.timestamp})
+	}
+	d.cacheMu.RUnlock()
+	sort.Slice(state.Liquidity, func(i, j int) bool {
+		return state.Liquidity[i].AssetID < state.Liquidity[j].AssetID
+	})
+
+	return state
+}
+
+// Restore loads a checkpoint taken by Checkpoint. It must be called before
+// trades are processed. Expired dedupe entries are dropped, and liquidity
+// keeps its original fetch time so stale books are looked up again.
+func (d *Detector) Restore(state *types.DetectorState) {
+	for _, s := range state.Seen {
+		d.seenTxHashes.restore(s.Hash, s.ExpiresAt)
+	}
+
+	d.statsMu.Lock()
+	for _, s := range state.Stats {
+		s := s
+		d.stats[s.ConditionID] = &s
+	}
+	d.statsMu.Unlock()
+
+	d.cacheMu.Lock()
+	for _, l := range state.Liquidity {
+		if existing, ok := d.liquidityCache[l.AssetID]; !ok || existing.timestamp.Before(l.FetchedAt) {
+			d.liquidityCache[l.AssetID] = liquidityEntry{value: l.USD, timestamp: l.FetchedAt}
+		}
+	}
+	d.cacheMu.Unlock()
+}
@@ -1,18 +1,33 @@
 package detector
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/mikefdy/polymarket-tool/internal/api"
 	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
 	"github.com/mikefdy/polymarket-tool/internal/types"
 )
 
-type DetectionHandler func(detection types.DetectedTrade)
+// maxSeenTxHashes bounds the historical trade dedupe set regardless of TTL.
+const maxSeenTxHashes = 100_000
+
+// DetectionHandler receives detections with a context carrying the span of
+// the trade that triggered them.
+type DetectionHandler func(ctx context.Context, detection types.DetectedTrade)
+
+// TradeHandler observes every WebSocket trade on a watched market.
+type TradeHandler func(trade types.MarketTrade)
 
 type Detector struct {
 	cfg            *config.Config
@@ -20,12 +35,15 @@ type Detector struct {
 	markets        map[string]*types.Market
 	assetToMarket  map[string]*types.Market
 	liquidityCache map[string]liquidityEntry
-	seenTxHashes   map[string]bool
+	seenTxHashes   *seenSet
 	whaleAddresses map[string]bool
 	whaleNames     map[string]string
 	onDetection    DetectionHandler
+	onTrade        TradeHandler
+	stats          map[string]*types.MarketStats
 	mu             sync.RWMutex
 	cacheMu        sync.RWMutex
+	statsMu        sync.RWMutex
 }
 
 type liquidityEntry struct {
@@ -40,13 +58,20 @@ func New(cfg *config.Config, apiClient *api.Client, onDetection DetectionHandler
 		markets:        make(map[string]*types.Market),
 		assetToMarket:  make(map[string]*types.Market),
 		liquidityCache: make(map[string]liquidityEntry),
-		seenTxHashes:   make(map[string]bool),
+		seenTxHashes:   newSeenSet(cfg.DedupeTTL, maxSeenTxHashes),
 		whaleAddresses: make(map[string]bool),
 		whaleNames:     make(map[string]string),
 		onDetection:    onDetection,
+		stats:          make(map[string]*types.MarketStats),
 	}
 }
 
+// SetTradeHandler registers h to be called for every trade on a watched
+// market. It must be called before trades are processed.
+func (d *Detector) SetTradeHandler(h TradeHandler) {
+	d.onTrade = h
+}
+
 func (d *Detector) SetWhales(whales []types.Whale) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -79,6 +104,35 @@ func (d *Detector) AddMarkets(markets []types.Market) []string {
 	return assetIDs
 }
 
+// RemoveMarkets stops watching the given markets and returns the asset IDs
+// that should be unsubscribed.
+func (d *Detector) RemoveMarkets(conditionIDs []string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var assetIDs []string
+	for _, id := range conditionIDs {
+		m, ok := d.markets[id]
+		if !ok {
+			continue
+		}
+		delete(d.markets, id)
+
+		for _, tokenID := range parseTokenIDs(m.ClobTokens) {
+			delete(d.assetToMarket, tokenID)
+			assetIDs = append(assetIDs, tokenID)
+		}
+	}
+
+	d.statsMu.Lock()
+	for _, id := range conditionIDs {
+		delete(d.stats, id)
+	}
+	d.statsMu.Unlock()
+
+	return assetIDs
+}
+
 func (d *Detector) GetWatchedConditionIDs() map[string]bool {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -90,7 +144,20 @@ func (d *Detector) GetWatchedConditionIDs() map[string]bool {
 	return ids
 }
 
-func (d *Detector) ProcessWsTrade(msg types.WsMessage) {
+// MarketForAsset returns the watched market trading assetID and the outcome
+// the asset represents, or nil if the asset is not watched.
+func (d *Detector) MarketForAsset(assetID string) (*types.Market, string) {
+	d.mu.RLock()
+	market := d.assetToMarket[assetID]
+	d.mu.RUnlock()
+
+	if market == nil {
+		return nil, ""
+	}
+	return market, outcomeFor(market, assetID)
+}
+
+func (d *Detector) ProcessWsTrade(ctx context.Context, msg types.WsMessage) {
 	d.mu.RLock()
 	market := d.assetToMarket[msg.AssetID]
 	d.mu.RUnlock()
@@ -99,6 +166,13 @@ func (d *Detector) ProcessWsTrade(msg types.WsMessage) {
 		return
 	}
 
+	ctx, span := tracing.Tracer("detector").Start(ctx, "detector.ProcessWsTrade",
+		trace.WithAttributes(
+			attribute.String("polymarket.condition_id", market.ConditionID),
+			attribute.String("polymarket.asset_id", msg.AssetID),
+		))
+	defer span.End()
+
 	price, _ := strconv.ParseFloat(msg.Price, 64)
 	size, _ := strconv.ParseFloat(msg.Size, 64)
 	if size == 0 {
@@ -106,27 +180,50 @@ func (d *Detector) ProcessWsTrade(msg types.WsMessage) {
 	}
 
 	usdValue := price * size
-	reasons := d.checkDetectionCriteria(market, msg.AssetID, usdValue, "")
-
-	if len(reasons) > 0 {
-		d.onDetection(types.DetectedTrade{
-			Market:    market,
-			AssetID:   msg.AssetID,
-			Side:      msg.Side,
-			Price:     price,
-			Size:      size,
-			UsdValue:  usdValue,
-			Timestamp: msg.Timestamp,
-			Reason:    strings.Join(reasons, " | "),
+	span.SetAttributes(attribute.Float64("polymarket.usd_value", usdValue))
+	d.recordTrade(market, msg.AssetID, msg.Side, price, usdValue)
+
+	if d.onTrade != nil {
+		d.onTrade(types.MarketTrade{
+			Market:     market,
+			AssetID:    msg.AssetID,
+			Side:       msg.Side,
+			Price:      price,
+			Size:       size,
+			UsdValue:   usdValue,
+			Timestamp:  msg.Timestamp,
+			ReceivedAt: time.Now(),
 		})
 	}
+
+	c := d.checkDetectionCriteria(ctx, market, msg.AssetID, usdValue, "")
+
+	if len(c.reasons) > 0 {
+		detection := types.DetectedTrade{
+			Market:      market,
+			AssetID:     msg.AssetID,
+			Side:        msg.Side,
+			Price:       price,
+			Size:        size,
+			UsdValue:    usdValue,
+			Timestamp:   msg.Timestamp,
+			Reason:      strings.Join(c.reasons, " | "),
+			Reasons:     c.reasons,
+			ReasonTypes: c.kinds,
+			Severity:    d.severity(usdValue, c),
+			DetectedAt:  time.Now(),
+		}
+		if ms, err := strconv.ParseInt(msg.Timestamp, 10, 64); err == nil {
+			metrics.DetectionLatency.Observe(detection.DetectedAt.Sub(time.UnixMilli(ms)).Seconds())
+		}
+		d.emit(ctx, detection)
+	}
 }
 
 func (d *Detector) ProcessHistoricalTrade(trade types.Trade) bool {
-	if d.seenTxHashes[trade.TransactionHash] {
+	if !d.seenTxHashes.Add(trade.TransactionHash) {
 		return false
 	}
-	d.seenTxHashes[trade.TransactionHash] = true
 
 	watchedIDs := d.GetWatchedConditionIDs()
 	if !watchedIDs[trade.ConditionID] {
@@ -142,42 +239,82 @@ func (d *Detector) ProcessHistoricalTrade(trade types.Trade) bool {
 	}
 
 	usdValue := trade.Price * trade.Size
-	reasons := d.checkDetectionCriteria(market, trade.Asset, usdValue, trade.ProxyWallet)
+	ctx := context.Background()
+	c := d.checkDetectionCriteria(ctx, market, trade.Asset, usdValue, trade.ProxyWallet)
 
-	if len(reasons) > 0 {
+	if len(c.reasons) > 0 {
 		trader := trade.Name
 		if trader == "" {
 			trader = trade.Pseudonym
 		}
-		d.onDetection(types.DetectedTrade{
-			Market:    market,
-			AssetID:   trade.Asset,
-			Side:      strings.ToLower(trade.Side),
-			Price:     trade.Price,
-			Size:      trade.Size,
-			UsdValue:  usdValue,
-			Timestamp: strconv.FormatInt(trade.Timestamp*1000, 10),
-			Reason:    "[HISTORICAL] " + strings.Join(reasons, " | "),
-			Wallet:    trade.ProxyWallet,
-			Trader:    trader,
+		d.emit(ctx, types.DetectedTrade{
+			Market:      market,
+			AssetID:     trade.Asset,
+			Side:        strings.ToLower(trade.Side),
+			Price:       trade.Price,
+			Size:        trade.Size,
+			UsdValue:    usdValue,
+			Timestamp:   strconv.FormatInt(trade.Timestamp*1000, 10),
+			Reason:      "[HISTORICAL] " + strings.Join(c.reasons, " | "),
+			Reasons:     c.reasons,
+			ReasonTypes: c.kinds,
+			Severity:    d.severity(usdValue, c),
+			Wallet:      trade.ProxyWallet,
+			Trader:      trader,
+			WhaleName:   c.whale,
+			DetectedAt:  time.Now(),
 		})
 		return true
 	}
 	return false
 }
 
-func (d *Detector) checkDetectionCriteria(market *types.Market, assetID string, usdValue float64, wallet string) []string {
-	var reasons []string
+// emit records a detection in the stats and metrics and hands it on.
+func (d *Detector) emit(ctx context.Context, detection types.DetectedTrade) {
+	trace.SpanFromContext(ctx).AddEvent("detection", trace.WithAttributes(
+		attribute.StringSlice("polymarket.reasons", detection.ReasonTypes),
+		attribute.String("polymarket.severity", detection.Severity),
+	))
+	d.recordDetection(detection.Market)
+	logging.For("detector").Debug("detection",
+		"conditionId", detection.Market.ConditionID,
+		"usd", detection.UsdValue,
+		"reasons", detection.ReasonTypes,
+		"severity", detection.Severity)
+	for _, kind := range detection.ReasonTypes {
+		metrics.Detections.WithLabelValues(kind, detection.Severity).Inc()
+	}
+	d.onDetection(ctx, detection)
+}
+
+// criteria is the outcome of evaluating a trade against the detection rules:
+// human-readable reasons alongside their machine-readable kinds.
+type criteria struct {
+	reasons []string
+	kinds   []string
+	whale   string
+}
+
+func (c *criteria) add(kind, reason string) {
+	c.kinds = append(c.kinds, kind)
+	c.reasons = append(c.reasons, reason)
+}
+
+func (d *Detector) checkDetectionCriteria(ctx context.Context, market *types.Market, assetID string, usdValue float64, wallet string) criteria {
+	ctx, span := tracing.Tracer("detector").Start(ctx, "detector.checkDetectionCriteria")
+	defer span.End()
+
+	var c criteria
 
 	if usdValue >= d.cfg.MinTradeUSD {
-		reasons = append(reasons, formatUSD("Large trade: ", usdValue))
+		c.add(types.ReasonLarge, formatUSD("Large trade: ", usdValue))
 	}
 
-	liquidity := d.getLiquidity(assetID)
+	liquidity := d.getLiquidity(ctx, assetID)
 	if liquidity > 0 {
 		ratio := usdValue / liquidity
 		if ratio >= d.cfg.MinLiquidityRatio {
-			reasons = append(reasons, formatPercent(ratio*100)+"% of book liquidity")
+			c.add(types.ReasonLiquidity, formatPercent(ratio*100)+"% of book liquidity")
 		}
 	}
 
@@ -186,7 +323,7 @@ func (d *Detector) checkDetectionCriteria(market *types.Market, assetID string,
 		if err == nil {
 			marketAge := time.Since(createdAt)
 			if marketAge < 24*time.Hour && usdValue >= d.cfg.MinTradeUSD/2 {
-				reasons = append(reasons, "Early market (<24h old)")
+				c.add(types.ReasonEarly, "Early market (<24h old)")
 			}
 		}
 	}
@@ -201,28 +338,52 @@ func (d *Detector) checkDetectionCriteria(market *types.Market, assetID string,
 			if whaleName == "" {
 				whaleName = wallet[:10]
 			}
-			reasons = append(reasons, "🐋 Whale: "+whaleName)
-		}
-
-		if isWhale && len(reasons) == 1 {
-			return reasons
+			c.add(types.ReasonWhale, "🐋 Whale: "+whaleName)
+			c.whale = whaleName
 		}
 	}
 
-	return reasons
+	span.SetAttributes(attribute.StringSlice("polymarket.reasons", c.kinds))
+	return c
 }
 
-func (d *Detector) getLiquidity(assetID string) float64 {
+// severity ranks a detection: trades at or above CriticalTradeUSD are
+// critical, whale trades and trades tripping several criteria are warnings,
+// and everything else is informational.
+func (d *Detector) severity(usdValue float64, c criteria) string {
+	return severity(d.cfg, usdValue, c)
+}
+
+func severity(cfg *config.Config, usdValue float64, c criteria) string {
+	switch {
+	case cfg.CriticalTradeUSD > 0 && usdValue >= cfg.CriticalTradeUSD:
+		return types.SeverityCritical
+	case c.whale != "" || len(c.kinds) > 1:
+		return types.SeverityWarning
+	default:
+		return types.SeverityInfo
+	}
+}
+
+func (d *Detector) getLiquidity(ctx context.Context, assetID string) float64 {
+	ctx, span := tracing.Tracer("detector").Start(ctx, "detector.getLiquidity")
+	defer span.End()
+
 	d.cacheMu.RLock()
 	entry, ok := d.liquidityCache[assetID]
 	d.cacheMu.RUnlock()
 
 	if ok && time.Since(entry.timestamp) < time.Minute {
+		metrics.LiquidityCache.WithLabelValues("hit").Inc()
+		span.SetAttributes(attribute.Bool("polymarket.cache_hit", true))
 		return entry.value
 	}
+	metrics.LiquidityCache.WithLabelValues("miss").Inc()
+	span.SetAttributes(attribute.Bool("polymarket.cache_hit", false))
 
-	book, err := d.api.GetOrderBook(assetID)
+	book, err := d.api.WithContext(ctx).GetOrderBook(assetID)
 	if err != nil {
+		logging.For("detector").Warn("order book lookup failed, skipping liquidity check", "asset", assetID, "err", err)
 		return 0
 	}
 
@@ -0,0 +1,103 @@
+package detector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// seenSet remembers keys for a fixed TTL, holding at most max of them. Every
+// entry has the same lifetime, so insertion order is expiry order and a FIFO
+// queue is enough to evict them.
+type seenSet struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	max   int
+	seen  map[string]time.Time
+	order []string
+}
+
+// defaultSeenTTL replaces a TTL of zero or less, which would expire every
+// key at once and so turn deduplication off.
+const defaultSeenTTL = 6 * time.Hour
+
+func newSeenSet(ttl time.Duration, max int) *seenSet {
+	if ttl <= 0 {
+		ttl = defaultSeenTTL
+	}
+	return &seenSet{
+		ttl:  ttl,
+		max:  max,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// Add records key and reports whether it was new.
+func (s *seenSet) Add(key string) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(now)
+
+	if _, ok := s.seen[key]; ok {
+		return false
+	}
+	s.seen[key] = now.Add(s.ttl)
+	s.order = append(s.order, key)
+	return true
+}
+
+func (s *seenSet) evict(now time.Time) {
+	n := 0
+	for n < len(s.order) {
+		key := s.order[n]
+		if len(s.order)-n <= s.max && now.Before(s.seen[key]) {
+			break
+		}
+		delete(s.seen, key)
+		n++
+	}
+	// Reslicing leaves the evicted prefix in the backing array until the
+	// next append reallocates, which copies only live keys.
+	s.order = s.order[n:]
+}
+
+// snapshot returns the live keys with their expiry, oldest first.
+func (s *seenSet) snapshot() []types.SeenTrade {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evict(now)
+	keys := make([]types.SeenTrade, 0, len(s.order))
+	for _, key := range s.order {
+		keys = append(keys, types.SeenTrade{Hash: key, ExpiresAt: s.seen[key]})
+	}
+	return keys
+}
+
+// restore adds key with its original expiry, capped at the current TTL. Keys
+// must be restored oldest first to keep the queue in expiry order.
+func (s *seenSet) restore(key string, expires time.Time) {
+	now := time.Now()
+	if !now.Before(expires) {
+		return
+	}
+	if max := now.Add(s.ttl); expires.After(max) {
+		expires = max
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.seen[key]; ok {
+		return
+	}
+	s.seen[key] = expires
+	s.order = append(s.order, key)
+	s.evict(now)
+}
@@ -0,0 +1,96 @@
+package detector
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+func (d *Detector) recordTrade(market *types.Market, assetID, side string, price, usdValue float64) {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	s := d.marketStats(market)
+	s.Trades++
+	s.VolumeUSD += usdValue
+	if strings.EqualFold(side, "sell") {
+		s.SellUSD += usdValue
+	} else {
+		s.BuyUSD += usdValue
+	}
+	s.LastPrice = price
+	s.LastOutcome = outcomeFor(market, assetID)
+	s.LastTradeAt = time.Now()
+}
+
+func (d *Detector) recordDetection(market *types.Market) {
+	d.statsMu.Lock()
+	d.marketStats(market).Detections++
+	d.statsMu.Unlock()
+}
+
+// marketStats returns the stats entry for market, creating it if needed.
+// statsMu must be held.
+func (d *Detector) marketStats(market *types.Market) *types.MarketStats {
+	s, ok := d.stats[market.ConditionID]
+	if !ok {
+		s = &types.MarketStats{
+			ConditionID: market.ConditionID,
+			Slug:        market.Slug,
+			EventSlug:   market.EventSlug(),
+			Question:    market.Question,
+		}
+		d.stats[market.ConditionID] = s
+	}
+	return s
+}
+
+// Stats returns live stats for every watched market, busiest first. Markets
+// without trades yet are included with zero counts.
+func (d *Detector) Stats() []types.MarketStats {
+	d.mu.RLock()
+	markets := make([]*types.Market, 0, len(d.markets))
+	for _, m := range d.markets {
+		markets = append(markets, m)
+	}
+	d.mu.RUnlock()
+
+	d.statsMu.RLock()
+	result := make([]types.MarketStats, 0, len(markets))
+	for _, m := range markets {
+		if s, ok := d.stats[m.ConditionID]; ok {
+			result = append(result, *s)
+		} else {
+			result = append(result, types.MarketStats{
+				ConditionID: m.ConditionID,
+				Slug:        m.Slug,
+				EventSlug:   m.EventSlug(),
+				Question:    m.Question,
+			})
+		}
+	}
+	d.statsMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].VolumeUSD != result[j].VolumeUSD {
+			return result[i].VolumeUSD > result[j].VolumeUSD
+		}
+		return result[i].ConditionID < result[j].ConditionID
+	})
+	return result
+}
+
+func outcomeFor(market *types.Market, assetID string) string {
+	var outcomes []string
+	json.Unmarshal([]byte(market.Outcomes), &outcomes)
+
+	for i, tokenID := range parseTokenIDs(market.ClobTokens) {
+		if tokenID == assetID && i < len(outcomes) {
+			return outcomes[i]
+		}
+	}
+	return ""
+}
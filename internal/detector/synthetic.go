@@ -0,0 +1,64 @@
+package detector
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// SyntheticTrade describes a made-up trade for Synthetic.
+type SyntheticTrade struct {
+	Market   *types.Market
+	AssetID  string
+	Side     string
+	Price    float64
+	UsdValue float64
+	// Wallet and WhaleName mark the trade as a tracked whale's.
+	Wallet    string
+	WhaleName string
+}
+
+// Synthetic builds the detection a live trade like t would produce under
+// cfg, for testing notification setups. The order book liquidity check is
+// skipped, and the reason is marked [TEST] so nobody mistakes it for a real
+// alert.
+func Synthetic(cfg *config.Config, t SyntheticTrade) types.DetectedTrade {
+	var c criteria
+	if t.UsdValue >= cfg.MinTradeUSD {
+		c.add(types.ReasonLarge, formatUSD("Large trade: ", t.UsdValue))
+	}
+	if t.WhaleName != "" {
+		c.add(types.ReasonWhale, "🐋 Whale: "+t.WhaleName)
+		c.whale = t.WhaleName
+	}
+	if len(c.reasons) == 0 {
+		// Below every threshold; still say why it was sent.
+		c.add(types.ReasonTest, formatUSD("Trade: ", t.UsdValue))
+	}
+
+	size := 0.0
+	if t.Price > 0 {
+		size = t.UsdValue / t.Price
+	}
+	now := time.Now()
+	return types.DetectedTrade{
+		Market:      t.Market,
+		AssetID:     t.AssetID,
+		Side:        strings.ToLower(t.Side),
+		Price:       t.Price,
+		Size:        size,
+		UsdValue:    t.UsdValue,
+		Timestamp:   strconv.FormatInt(now.UnixMilli(), 10),
+		Reason:      "[TEST] " + strings.Join(c.reasons, " | "),
+		Reasons:     c.reasons,
+		ReasonTypes: c.kinds,
+		Severity:    severity(cfg, t.UsdValue, c),
+		Wallet:      t.Wallet,
+		Trader:      t.WhaleName,
+		WhaleName:   t.WhaleName,
+		DetectedAt:  now,
+	}
+}
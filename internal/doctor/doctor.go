@@ -0,0 +1,442 @@
+// Package doctor diagnoses a tracker setup: configuration, reachability of
+// the Polymarket APIs and WebSocket, the data directory and every notifier
+// sink. Each check passes, warns or fails with a hint on how to fix it.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/notifier"
+	"github.com/mikefdy/polymarket-tool/internal/pipeline"
+	"github.com/mikefdy/polymarket-tool/internal/report"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// slowAfter is the latency above which a reachable endpoint still warns.
+const slowAfter = 2 * time.Second
+
+type Status string
+
+const (
+	Pass Status = "PASS"
+	Warn Status = "WARN"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Result is the outcome of one check. Hint is set when it did not pass.
+type Result struct {
+	Group   string
+	Check   string
+	Status  Status
+	Latency time.Duration
+	Detail  string
+	Hint    string
+}
+
+type Options struct {
+	// Timeout bounds each network check.
+	Timeout time.Duration
+	// SkipSinks leaves out the test message to each sink.
+	SkipSinks bool
+}
+
+type doctor struct {
+	cfg     *config.Config
+	opts    Options
+	http    *http.Client
+	results []Result
+	// assetID is a live asset found while probing Gamma, used for the
+	// WebSocket test subscription.
+	assetID string
+}
+
+// Run performs every check in order.
+func Run(cfg *config.Config, opts Options) []Result {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	d := &doctor{cfg: cfg, opts: opts, http: &http.Client{Timeout: opts.Timeout}}
+	add := func(r Result) {
+		d.results = append(d.results, r)
+	}
+
+	add(d.checkEnv())
+	add(d.checkSettings())
+
+	notifyCfg, nr := d.checkNotifyConfig()
+	add(nr)
+	notify, nr := d.checkNotifier(notifyCfg)
+	add(nr)
+
+	add(d.checkAPI("gamma api", cfg.GammaURL+"/markets?limit=1&active=true&closed=false&order=volume24hr&ascending=false", d.findAsset))
+	add(d.checkAPI("clob api", cfg.ClobURL+"/time", nil))
+	add(d.checkAPI("data api", cfg.DataAPIURL+"/trades?limit=1", nil))
+	add(d.checkWebSocket())
+
+	add(d.checkDataDir())
+	for _, r := range d.checkDataFiles() {
+		add(r)
+	}
+
+	for _, r := range d.checkSinks(notify) {
+		add(r)
+	}
+	if notify != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		notify.Close(ctx)
+		cancel()
+	}
+	return d.results
+}
+
+// Failed reports whether any result failed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// ============= CONFIG =============
+
+func (d *doctor) checkEnv() Result {
+	r := Result{Group: "config", Check: "environment", Status: Pass, Detail: "all variables parse"}
+	if invalid := config.InvalidEnv(); len(invalid) > 0 {
+		r.Status = Fail
+		r.Detail = strings.Join(invalid, "; ")
+		r.Hint = "These fall back to their defaults. Numbers look like 1000 or 0.05, durations like 30s, 5m or 6h, booleans true or false."
+	}
+	return r
+}
+
+func (d *doctor) checkSettings() Result {
+	cfg := d.cfg
+	var problems []string
+	if cfg.MinTradeUSD <= 0 {
+		problems = append(problems, "MIN_TRADE_USD must be positive")
+	}
+	if cfg.MinLiquidityRatio <= 0 || cfg.MinLiquidityRatio > 1 {
+		problems = append(problems, "MIN_LIQUIDITY_RATIO must be between 0 and 1")
+	}
+	if cfg.CriticalTradeUSD > 0 && cfg.CriticalTradeUSD < cfg.MinTradeUSD {
+		problems = append(problems, "CRITICAL_TRADE_USD is below MIN_TRADE_USD, so every large trade is critical")
+	}
+	if cfg.PollIntervalMs < 1000 {
+		problems = append(problems, "POLL_INTERVAL_MS below 1000 hammers the Gamma API")
+	}
+	if cfg.PipelineWorkers < 1 || cfg.PipelineQueueSize < 1 {
+		problems = append(problems, "PIPELINE_WORKERS and PIPELINE_QUEUE_SIZE must be at least 1")
+	}
+	if cfg.PipelineOverflow != pipeline.OverflowDropOldest && cfg.PipelineOverflow != pipeline.OverflowBlock {
+		problems = append(problems, fmt.Sprintf("PIPELINE_OVERFLOW %q is not drop-oldest or block", cfg.PipelineOverflow))
+	}
+	switch cfg.TraceExporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, "":
+	default:
+		problems = append(problems, fmt.Sprintf("TRACE_EXPORTER %q is not otlp, stdout or none", cfg.TraceExporter))
+	}
+	if cfg.TraceSampleRatio < 0 || cfg.TraceSampleRatio > 1 {
+		problems = append(problems, "TRACE_SAMPLE_RATIO must be between 0 and 1")
+	}
+	for period, spec := range map[string]string{report.Daily: cfg.ReportDaily, report.Weekly: cfg.ReportWeekly} {
+		if spec == "" {
+			continue
+		}
+		if _, err := report.ParseSchedule(period, spec); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	for name, raw := range map[string]string{"GAMMA": cfg.GammaURL, "CLOB": cfg.ClobURL, "DATA": cfg.DataAPIURL, "WS": cfg.ClobWsURL} {
+		if u, err := url.Parse(raw); err != nil || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s URL %q is invalid", name, raw))
+		}
+	}
+
+	r := Result{Group: "config", Check: "settings", Status: Pass,
+		Detail: fmt.Sprintf("min trade %s, liquidity ratio %.0f%%, %d search queries", formatUSD(cfg.MinTradeUSD), cfg.MinLiquidityRatio*100, len(cfg.SearchQueries))}
+	if len(problems) > 0 {
+		r.Status = Fail
+		r.Detail = strings.Join(problems, "; ")
+		r.Hint = "Adjust the variables named above; run `polymarket-tool help` for their meaning."
+	}
+	return r
+}
+
+func (d *doctor) checkNotifyConfig() (*types.NotifyConfig, Result) {
+	r := Result{Group: "config", Check: "notify.json", Status: Pass}
+	nc, err := storage.LoadNotifyConfig()
+	switch {
+	case err != nil:
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "Fix the JSON in " + storage.Dir() + "/notify.json (see Routing in the README), or move it aside to use the environment sinks only."
+	case nc == nil:
+		r.Detail = "not present, every detection goes to the environment sinks"
+	default:
+		r.Detail = fmt.Sprintf("%d sinks, %d routes", len(nc.Sinks), len(nc.Routes))
+	}
+	return nc, r
+}
+
+func (d *doctor) checkNotifier(nc *types.NotifyConfig) (*notifier.Notifier, Result) {
+	r := Result{Group: "config", Check: "notifier", Status: Pass}
+	notify, err := notifier.New(d.cfg, nc)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "Check the sink named in the error: its environment variables or its entry in notify.json (url, template, quietHours, digest)."
+		return nil, r
+	}
+	r.Detail = "sinks: " + strings.Join(notify.Sinks(), ", ")
+	return notify, r
+}
+
+// ============= NETWORK =============
+
+// checkAPI fetches probeURL and, when it answers, hands the body to inspect.
+func (d *doctor) checkAPI(name, probeURL string, inspect func([]byte)) Result {
+	r := Result{Group: "network", Check: name}
+	start := time.Now()
+	resp, err := d.http.Get(probeURL)
+	r.Latency = time.Since(start)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "Check DNS, proxy and firewall access to " + hostOf(probeURL) + " over HTTPS."
+		return r
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		r.Status = Fail
+		r.Detail = "HTTP " + resp.Status
+		r.Hint = "The API answered with an error; check status.polymarket.com or retry later."
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == 451 {
+			r.Hint = "Access is refused from this network or region; try another network."
+		}
+		return r
+	}
+
+	if inspect != nil {
+		var body json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+			inspect(body)
+		}
+	}
+
+	r.Status = Pass
+	r.Detail = "HTTP 200"
+	if r.Latency > slowAfter {
+		r.Status = Warn
+		r.Detail = "HTTP 200, slow"
+		r.Hint = "Responses over " + slowAfter.String() + " delay detections; check the network path."
+	}
+	return r
+}
+
+// findAsset picks the first asset of the busiest active market from a Gamma
+// /markets response.
+func (d *doctor) findAsset(body []byte) {
+	var markets []types.Market
+	if json.Unmarshal(body, &markets) != nil {
+		return
+	}
+	for _, m := range markets {
+		var ids []string
+		if json.Unmarshal([]byte(m.ClobTokens), &ids) == nil && len(ids) > 0 {
+			d.assetID = ids[0]
+			return
+		}
+	}
+}
+
+// checkWebSocket connects to the market channel and, when an asset is known,
+// subscribes to it and waits for the first message.
+func (d *doctor) checkWebSocket() Result {
+	r := Result{Group: "network", Check: "websocket"}
+	dialer := websocket.Dialer{HandshakeTimeout: d.opts.Timeout}
+
+	start := time.Now()
+	conn, _, err := dialer.Dial(d.cfg.ClobWsURL, nil)
+	r.Latency = time.Since(start)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "Check that outbound WebSocket (wss://) connections to " + hostOf(d.cfg.ClobWsURL) + " are allowed; some proxies block upgrades."
+		return r
+	}
+	defer conn.Close()
+
+	if d.assetID == "" {
+		r.Status = Warn
+		r.Detail = "connected, but no asset to test a subscription with"
+		r.Hint = "The Gamma API check did not return a market; fix it first."
+		return r
+	}
+
+	sub := map[string]interface{}{"assets_ids": []string{d.assetID}, "type": "market"}
+	if err := conn.WriteJSON(sub); err != nil {
+		r.Status = Fail
+		r.Detail = "subscribe failed: " + err.Error()
+		r.Hint = "The connection dropped right after opening; retry, and check for an intercepting proxy."
+		return r
+	}
+
+	conn.SetReadDeadline(time.Now().Add(d.opts.Timeout))
+	subscribed := time.Now()
+	if _, _, err := conn.ReadMessage(); err != nil {
+		r.Status = Fail
+		r.Detail = "no message after subscribing: " + err.Error()
+		r.Hint = "The subscription was accepted but nothing arrived; the feed may be degraded, or a proxy is buffering frames."
+		return r
+	}
+
+	r.Status = Pass
+	r.Detail = fmt.Sprintf("connected, first message %s after subscribing", time.Since(subscribed).Round(time.Millisecond))
+	if r.Latency > slowAfter {
+		r.Status = Warn
+		r.Hint = "Slow handshake; check the network path."
+	}
+	return r
+}
+
+// ============= STORAGE =============
+
+func (d *doctor) checkDataDir() Result {
+	r := Result{Group: "storage", Check: storage.Dir() + "/", Status: Pass, Detail: "writable"}
+	if err := storage.CheckWritable(); err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "Run from a directory you can write to, or fix the permissions of " + storage.Dir() + "/."
+	}
+	return r
+}
+
+func (d *doctor) checkDataFiles() []Result {
+	file := func(name string, load func() (int, error), noun string) Result {
+		r := Result{Group: "storage", Check: storage.Dir() + "/" + name, Status: Pass}
+		n, err := load()
+		if err != nil {
+			r.Status = Fail
+			r.Detail = err.Error()
+			r.Hint = "Fix the JSON, or move the file aside to start over."
+			return r
+		}
+		r.Detail = fmt.Sprintf("%d %s", n, noun)
+		return r
+	}
+
+	return []Result{
+		file("whales.json", func() (int, error) {
+			w, err := storage.LoadWhales()
+			return len(w), err
+		}, "whales"),
+		file("markets.json", func() (int, error) {
+			m, err := storage.LoadMarkets()
+			return len(m), err
+		}, "markets"),
+		file("mutes.json", func() (int, error) {
+			m, err := storage.LoadMutes()
+			return len(m), err
+		}, "mutes"),
+		file("state.json", func() (int, error) {
+			state, err := storage.LoadDetectorState()
+			if state == nil {
+				return 0, err
+			}
+			return len(state.Stats), err
+		}, "markets checkpointed"),
+		d.checkDetectionLog(),
+	}
+}
+
+func (d *doctor) checkDetectionLog() Result {
+	r := Result{Group: "storage", Check: storage.Dir() + "/detections.jsonl", Status: Pass}
+	lines, err := storage.LoadDetections(0)
+	if err != nil {
+		r.Status = Fail
+		r.Detail = err.Error()
+		r.Hint = "The file can't be read; check its permissions, or move it aside."
+		return r
+	}
+
+	bad := 0
+	for _, line := range lines {
+		if !json.Valid(line) {
+			bad++
+		}
+	}
+	r.Detail = fmt.Sprintf("%d detections", len(lines))
+	if bad > 0 {
+		r.Status = Warn
+		r.Detail += fmt.Sprintf(", %d unreadable lines", bad)
+		r.Hint = "Unreadable lines are skipped by the API and reports; usually a write cut short by a crash."
+	}
+	return r
+}
+
+// ============= SINKS =============
+
+func (d *doctor) checkSinks(notify *notifier.Notifier) []Result {
+	if notify == nil {
+		return []Result{{Group: "sinks", Check: "all", Status: Skip, Detail: "notifier setup failed"}}
+	}
+
+	var results []Result
+	for _, name := range notify.Sinks() {
+		r := Result{Group: "sinks", Check: name}
+		if d.opts.SkipSinks {
+			r.Status = Skip
+			r.Detail = "--skip-sinks"
+			results = append(results, r)
+			continue
+		}
+
+		start := time.Now()
+		err := notify.Test(name, notifier.Message{
+			Kind:     notifier.KindTest,
+			Title:    "polymarket-tool doctor: test message",
+			Text:     "This sink is configured correctly.",
+			Severity: types.SeverityInfo,
+		})
+		r.Latency = time.Since(start)
+		if err != nil {
+			r.Status = Fail
+			r.Detail = err.Error()
+			r.Hint = "Check the sink's URL, credentials and that it is reachable from here."
+		} else {
+			r.Status = Pass
+			r.Detail = "ok"
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return raw
+}
+
+func formatUSD(v float64) string {
+	if v >= 1000 {
+		return fmt.Sprintf("$%.1fK", v/1000)
+	}
+	return fmt.Sprintf("$%.0f", v)
+}
@@ -0,0 +1,291 @@
+// Package health watches a running tracker for a dead or silent feed and
+// failing APIs. It backs /healthz and /readyz and sends "tracker unhealthy"
+// and "tracker recovered" messages through the notifier.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/api"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/notifier"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+	"github.com/mikefdy/polymarket-tool/internal/ws"
+)
+
+const (
+	// checkInterval is how often the checks run.
+	checkInterval = 15 * time.Second
+	// maxAPIFailures is how many requests in a row an API may fail before
+	// it is reported unhealthy.
+	maxAPIFailures = 3
+)
+
+// Check is the outcome of one health check.
+type Check struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// Status is the tracker's health as of the last check.
+type Status struct {
+	Healthy bool `json:"healthy"`
+	// Ready is set once the WebSocket is connected and every check passes.
+	Ready bool `json:"ready"`
+	// Since is when the tracker last changed between healthy and
+	// unhealthy, or when monitoring started.
+	Since time.Time `json:"since"`
+	// SilentSubscriptions counts subscribed assets with no message for
+	// longer than the stale threshold. Quiet markets are normal, so this
+	// alone does not make the tracker unhealthy.
+	SilentSubscriptions int       `json:"silentSubscriptions"`
+	Subscriptions       int       `json:"subscriptions"`
+	CheckedAt           time.Time `json:"checkedAt"`
+	Checks              []Check   `json:"checks"`
+}
+
+type Options struct {
+	// StaleAfter is how long the feed may be silent, or the WebSocket
+	// disconnected, before the tracker is unhealthy.
+	StaleAfter time.Duration
+	// Alerts enables unhealthy/recovered messages.
+	Alerts bool
+	// Sinks receive the messages; empty means every sink.
+	Sinks []string
+}
+
+type Monitor struct {
+	opts    Options
+	ws      *ws.Client
+	api     *api.Client
+	notify  *notifier.Notifier
+	started time.Time
+
+	mu             sync.RWMutex
+	status         Status
+	disconnectedAt time.Time
+}
+
+func New(opts Options, wsClient *ws.Client, apiClient *api.Client, notify *notifier.Notifier) *Monitor {
+	now := time.Now()
+	return &Monitor{
+		opts:    opts,
+		ws:      wsClient,
+		api:     apiClient,
+		notify:  notify,
+		started: now,
+		status:  Status{Healthy: true, Since: now},
+	}
+}
+
+// Run checks health every checkInterval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	m.Check()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Status returns the result of the last check.
+func (m *Monitor) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+// Check runs every check now, updates the status and alerts on a change
+// between healthy and unhealthy.
+func (m *Monitor) Check() Status {
+	now := time.Now()
+	st := m.ws.Status()
+
+	m.mu.Lock()
+	checks := []Check{m.checkWebSocket(st, now), m.checkFeed(st, now)}
+	checks = append(checks, m.checkAPIs()...)
+
+	healthy := true
+	for _, c := range checks {
+		metrics.HealthCheck.WithLabelValues(c.Name).Set(boolGauge(c.OK))
+		healthy = healthy && c.OK
+	}
+	metrics.Healthy.Set(boolGauge(healthy))
+
+	prev := m.status
+	status := Status{
+		Healthy:       healthy,
+		Ready:         healthy && st.Connected,
+		Since:         prev.Since,
+		Subscriptions: st.SubscribedAssets,
+		CheckedAt:     now,
+		Checks:        checks,
+	}
+	for _, last := range m.ws.LastMessages() {
+		if m.silent(last, now) {
+			status.SilentSubscriptions++
+		}
+	}
+	if healthy != prev.Healthy {
+		status.Since = now
+	}
+	m.status = status
+	m.mu.Unlock()
+
+	switch {
+	case prev.Healthy && !healthy:
+		m.alert(unhealthyMessage(status))
+	case !prev.Healthy && healthy:
+		m.alert(recoveredMessage(status, now.Sub(prev.Since)))
+	}
+	return status
+}
+
+// checkWebSocket fails once reconnects are exhausted or the socket has been
+// down longer than StaleAfter. Callers must hold m.mu.
+func (m *Monitor) checkWebSocket(st ws.Status, now time.Time) Check {
+	c := Check{Name: "websocket", OK: true, Message: "connected"}
+	switch {
+	case st.Connected:
+		m.disconnectedAt = time.Time{}
+	case st.GaveUp:
+		c.OK = false
+		c.Message = fmt.Sprintf("gave up reconnecting after %d attempts", st.Reconnects)
+	default:
+		if m.disconnectedAt.IsZero() {
+			m.disconnectedAt = now
+		}
+		down := now.Sub(m.disconnectedAt)
+		c.Message = "reconnecting, down for " + down.Round(time.Second).String()
+		c.OK = down < m.opts.StaleAfter
+	}
+	return c
+}
+
+// checkFeed fails when nothing has arrived on the market channel for longer
+// than StaleAfter while assets are subscribed.
+func (m *Monitor) checkFeed(st ws.Status, now time.Time) Check {
+	c := Check{Name: "feed", OK: true}
+	if st.SubscribedAssets == 0 {
+		c.Message = "no subscriptions"
+		return c
+	}
+
+	last := st.LastMessageAt
+	if last.IsZero() {
+		c.Message = "no messages yet"
+		last = m.started
+	} else {
+		c.Message = "last message " + now.Sub(last).Round(time.Second).String() + " ago"
+	}
+	if m.silent(last, now) {
+		c.OK = false
+		c.Message = "no messages for " + now.Sub(last).Round(time.Second).String()
+	}
+	return c
+}
+
+// checkAPIs reports every API used so far, failing those whose recent
+// requests all failed.
+func (m *Monitor) checkAPIs() []Check {
+	apis := m.api.Health()
+	names := make([]string, 0, len(apis))
+	for name := range apis {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]Check, 0, len(names))
+	for _, name := range names {
+		h := apis[name]
+		c := Check{Name: "api:" + name, OK: true, Message: "ok"}
+		if h.ConsecutiveFailures > 0 {
+			c.Message = fmt.Sprintf("%d failed requests in a row: %s", h.ConsecutiveFailures, h.LastError)
+			c.OK = h.ConsecutiveFailures < maxAPIFailures
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func (m *Monitor) silent(last, now time.Time) bool {
+	if last.IsZero() {
+		last = m.started
+	}
+	return now.Sub(last) > m.opts.StaleAfter
+}
+
+func (m *Monitor) alert(msg notifier.Message) {
+	logger := logging.For("health")
+	if msg.Severity == types.SeverityCritical {
+		logger.Error(msg.Title)
+	} else {
+		logger.Info(msg.Title)
+	}
+
+	if !m.opts.Alerts {
+		return
+	}
+	if err := m.notify.SendMessage(msg, m.opts.Sinks); err != nil {
+		logger.Error("health alert delivery failed", "err", err)
+	}
+}
+
+func unhealthyMessage(st Status) notifier.Message {
+	var failed []string
+	for _, c := range st.Checks {
+		if !c.OK {
+			failed = append(failed, c.Name+": "+c.Message)
+		}
+	}
+	return notifier.Message{
+		Kind:     notifier.KindHealth,
+		Title:    "Tracker unhealthy: " + strings.Join(failed, "; "),
+		Text:     checkList(st),
+		Severity: types.SeverityCritical,
+		Data:     st,
+	}
+}
+
+func recoveredMessage(st Status, down time.Duration) notifier.Message {
+	return notifier.Message{
+		Kind:     notifier.KindHealth,
+		Title:    "Tracker recovered after " + down.Round(time.Second).String(),
+		Text:     checkList(st),
+		Severity: types.SeverityInfo,
+		Data:     st,
+	}
+}
+
+func checkList(st Status) string {
+	var b strings.Builder
+	for _, c := range st.Checks {
+		mark := "✅"
+		if !c.OK {
+			mark = "❌"
+		}
+		fmt.Fprintf(&b, "%s %s: %s\n", mark, c.Name, c.Message)
+	}
+	fmt.Fprintf(&b, "%d of %d subscriptions silent", st.SilentSubscriptions, st.Subscriptions)
+	return b.String()
+}
+
+func boolGauge(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,45 @@
+// Package logging configures the process-wide slog logger. Diagnostics go to
+// stderr so stdout carries only detection output.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Setup installs the default logger writing to w at level ("debug", "info",
+// "warn" or "error") in format ("text" or "json"). The standard log package
+// is routed through it too.
+func Setup(w io.Writer, level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q (text, json)", format)
+	}
+
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// For returns the default logger tagged with component, e.g. "ws".
+func For(component string) *slog.Logger {
+	return slog.Default().With("component", component)
+}
@@ -0,0 +1,147 @@
+// Package metrics defines the Prometheus metrics exported by a running
+// tracker. They are registered with the default registry and served on
+// /metrics by `start --http`.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "polymarket"
+
+var (
+	WSMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "messages_total",
+		Help:      "Market channel messages received, by event type.",
+	}, []string{"event_type"})
+
+	WSReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "reconnects_total",
+		Help:      "WebSocket reconnect attempts.",
+	})
+
+	WSConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "connected",
+		Help:      "1 while the WebSocket is connected.",
+	})
+
+	WSSubscribedAssets = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ws",
+		Name:      "subscribed_assets",
+		Help:      "Assets subscribed on the market channel.",
+	})
+
+	Detections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "detections_total",
+		Help:      "Detections by reason and severity. A detection with several reasons counts once per reason.",
+	}, []string{"reason", "severity"})
+
+	DetectionLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "detection_latency_seconds",
+		Help:      "Time from a live trade's timestamp to its detection.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30, 60},
+	})
+
+	NotificationLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "notification_latency_seconds",
+		Help:      "Time from a trade's timestamp to a sink accepting its detection, by sink. Detections held in digests or quiet hours are not counted.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"sink"})
+
+	NotifierDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifier",
+		Name:      "deliveries_total",
+		Help:      "Detections and messages delivered, by sink.",
+	}, []string{"sink"})
+
+	NotifierFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifier",
+		Name:      "failures_total",
+		Help:      "Failed deliveries, by sink.",
+	}, []string{"sink"})
+
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Polymarket API request latency, by API (gamma, clob, data) and endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"api", "endpoint"})
+
+	APIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "errors_total",
+		Help:      "Failed Polymarket API requests, by API and endpoint.",
+	}, []string{"api", "endpoint"})
+
+	LiquidityCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "liquidity_cache",
+		Name:      "requests_total",
+		Help:      "Order book liquidity lookups, by result (hit or miss).",
+	}, []string{"result"})
+
+	HealthCheck = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "check_ok",
+		Help:      "1 while a health check passes, by check.",
+	}, []string{"check"})
+
+	Healthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "healthy",
+		Help:      "1 while every health check passes.",
+	})
+
+	PipelineQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pipeline",
+		Name:      "queue_depth",
+		Help:      "Items waiting in a pipeline stage's queue, by stage (detect or notify).",
+	}, []string{"stage"})
+
+	PipelineQueueCapacity = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "pipeline",
+		Name:      "queue_capacity",
+		Help:      "Capacity of a pipeline stage's queue, by stage.",
+	}, []string{"stage"})
+
+	PipelineDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "pipeline",
+		Name:      "dropped_total",
+		Help:      "Items dropped from a full pipeline queue under the drop-oldest policy, by stage.",
+	}, []string{"stage"})
+
+	PipelineWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "pipeline",
+		Name:      "queue_wait_seconds",
+		Help:      "Time items spend queued before a stage picks them up, by stage.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	}, []string{"stage"})
+)
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/nats-io/nats.go"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	defaultTopicPrefix = "polymarket"
+	natsReconnectBuf   = 8 * 1024 * 1024
+	probeTimeout       = 10 * time.Second
+)
+
+// TradePublisher is implemented by sinks that can also publish the raw trade
+// tape, not just detections.
+type TradePublisher interface {
+	PublishTrade(t types.MarketTrade) error
+}
+
+// topicToken makes s safe as a single MQTT topic level or NATS subject token.
+func topicToken(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '/', ' ', '*', '>', '+', '#':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// mqttSink publishes detections to <prefix>/detections/<eventSlug>, messages
+// to <prefix>/messages/<kind> and, when enabled, every trade to
+// <prefix>/trades/<eventSlug>. The client reconnects on its own and queues
+// QoS 1 publishes made while disconnected.
+type mqttSink struct {
+	name    string
+	prefix  string
+	trades  bool
+	client  mqtt.Client
+	pending sync.WaitGroup
+}
+
+func newMQTTSink(name, broker, username, password, prefix string, trades bool) *mqttSink {
+	if prefix == "" {
+		prefix = defaultTopicPrefix
+	}
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(fmt.Sprintf("polymarket-tool-%d", os.Getpid())).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(5 * time.Second).
+		SetMaxReconnectInterval(30 * time.Second).
+		SetOrderMatters(false).
+		SetOnConnectHandler(func(mqtt.Client) {
+			notifierLog(name).Info("connected", "broker", broker)
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			notifierLog(name).Warn("connection lost", "err", err)
+		})
+
+	client := mqtt.NewClient(opts)
+	// With connect retry enabled this returns immediately and keeps trying
+	// in the background.
+	client.Connect()
+
+	return &mqttSink{name: name, prefix: prefix, trades: trades, client: client}
+}
+
+func (s *mqttSink) Name() string { return s.name }
+
+func (s *mqttSink) Send(d types.DetectedTrade) error {
+	return s.publish(s.topic("detections", d.Market.EventSlug()), 1, NewPayload(d))
+}
+
+func (s *mqttSink) SendMessage(m Message) error {
+	return s.publish(s.topic("messages", m.Kind), 1, NewMessagePayload(m))
+}
+
+// PublishTrade sends trades at QoS 0: the tape is high volume and stale
+// trades are not worth queueing through a disconnect.
+func (s *mqttSink) PublishTrade(t types.MarketTrade) error {
+	if !s.trades || !s.client.IsConnectionOpen() {
+		return nil
+	}
+	return s.publish(s.topic("trades", t.Market.EventSlug()), 0, NewTradePayload(t))
+}
+
+// probe publishes m and waits for the broker to acknowledge it, since
+// publishes are otherwise fire-and-forget.
+func (s *mqttSink) probe(m Message) error {
+	return s.publishAndWait(s.topic("messages", m.Kind), NewMessagePayload(m))
+}
+
+func (s *mqttSink) sendAndWait(d types.DetectedTrade) error {
+	return s.publishAndWait(s.topic("detections", d.Market.EventSlug()), NewPayload(d))
+}
+
+func (s *mqttSink) publishAndWait(topic string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	token := s.client.Publish(topic, 1, false, body)
+	if !token.WaitTimeout(probeTimeout) {
+		return fmt.Errorf("no acknowledgement from broker within %s", probeTimeout)
+	}
+	return token.Error()
+}
+
+func (s *mqttSink) topic(kind, key string) string {
+	return s.prefix + "/" + kind + "/" + topicToken(key)
+}
+
+func (s *mqttSink) publish(topic string, qos byte, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	token := s.client.Publish(topic, qos, false, body)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		<-token.Done()
+		if err := token.Error(); err != nil {
+			notifierLog(s.name).Error("publish failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+// close waits for publishes to be acknowledged, then disconnects.
+func (s *mqttSink) close(ctx context.Context) error {
+	defer s.client.Disconnect(250)
+
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("publishes still unacknowledged: %w", ctx.Err())
+	}
+}
+
+// natsSink publishes to the same hierarchy as mqttSink using dots:
+// <prefix>.detections.<eventSlug>. Publishes made while reconnecting are
+// buffered by the client up to natsReconnectBuf bytes.
+type natsSink struct {
+	name   string
+	prefix string
+	trades bool
+	conn   *nats.Conn
+}
+
+func newNATSSink(name, url, username, password, prefix string, trades bool) (*natsSink, error) {
+	if prefix == "" {
+		prefix = defaultTopicPrefix
+	}
+
+	opts := []nats.Option{
+		nats.Name("polymarket-tool"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2 * time.Second),
+		nats.ReconnectBufSize(natsReconnectBuf),
+		nats.ConnectHandler(func(*nats.Conn) {
+			notifierLog(name).Info("connected", "url", url)
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				notifierLog(name).Warn("disconnected", "err", err)
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			notifierLog(name).Info("reconnected")
+		}),
+	}
+	if username != "" {
+		opts = append(opts, nats.UserInfo(username, password))
+	}
+
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &natsSink{name: name, prefix: prefix, trades: trades, conn: conn}, nil
+}
+
+func (s *natsSink) Name() string { return s.name }
+
+func (s *natsSink) Send(d types.DetectedTrade) error {
+	return s.publish(s.subject("detections", d.Market.EventSlug()), NewPayload(d))
+}
+
+func (s *natsSink) SendMessage(m Message) error {
+	return s.publish(s.subject("messages", m.Kind), NewMessagePayload(m))
+}
+
+// PublishTrade skips the tape while disconnected so trades do not crowd
+// detections out of the reconnect buffer.
+func (s *natsSink) PublishTrade(t types.MarketTrade) error {
+	if !s.trades || !s.conn.IsConnected() {
+		return nil
+	}
+	return s.publish(s.subject("trades", t.Market.EventSlug()), NewTradePayload(t))
+}
+
+// probe publishes m and waits for the server to process it.
+func (s *natsSink) probe(m Message) error {
+	if err := s.SendMessage(m); err != nil {
+		return err
+	}
+	return s.conn.FlushTimeout(probeTimeout)
+}
+
+func (s *natsSink) sendAndWait(d types.DetectedTrade) error {
+	if err := s.Send(d); err != nil {
+		return err
+	}
+	return s.conn.FlushTimeout(probeTimeout)
+}
+
+// close flushes buffered publishes to the server before disconnecting.
+func (s *natsSink) close(ctx context.Context) error {
+	defer s.conn.Close()
+	return s.conn.FlushWithContext(ctx)
+}
+
+func (s *natsSink) subject(kind, key string) string {
+	return s.prefix + "." + kind + "." + topicToken(key)
+}
+
+func (s *natsSink) publish(subject string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.conn.Publish(subject, body)
+}
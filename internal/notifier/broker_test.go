@@ -0,0 +1,337 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// published is one message received by a test broker.
+type published struct {
+	topic string
+	body  []byte
+}
+
+// testBroker collects what a fake broker receives.
+type testBroker struct {
+	addr string
+	mu   sync.Mutex
+	msgs []published
+}
+
+func (b *testBroker) add(topic string, body []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.msgs = append(b.msgs, published{topic, body})
+}
+
+func (b *testBroker) received() map[string][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	byTopic := make(map[string][]byte, len(b.msgs))
+	for _, m := range b.msgs {
+		byTopic[m.topic] = m.body
+	}
+	return byTopic
+}
+
+// listen accepts connections on a loopback port until the test ends,
+// handing each to serve.
+func listen(t *testing.T, serve func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// startNATS runs just enough of the NATS client protocol for a publisher:
+// INFO, CONNECT, PING/PONG and PUB.
+func startNATS(t *testing.T) *testBroker {
+	b := &testBroker{}
+	b.addr = listen(t, func(conn net.Conn) {
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.10.0\",\"proto\":1,\"max_payload\":1048576}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch strings.ToUpper(fields[0]) {
+			case "PING":
+				io.WriteString(conn, "PONG\r\n")
+			case "PUB":
+				// PUB <subject> [reply-to] <#bytes>
+				n, err := strconv.Atoi(fields[len(fields)-1])
+				if err != nil {
+					t.Errorf("bad PUB line %q", line)
+					return
+				}
+				body := make([]byte, n+2)
+				if _, err := io.ReadFull(r, body); err != nil {
+					return
+				}
+				b.add(fields[1], body[:n])
+			}
+		}
+	})
+	return b
+}
+
+// startMQTT runs just enough of MQTT 3.1.1 for a publisher: CONNECT,
+// PUBLISH at QoS 0 and 1, PINGREQ and DISCONNECT.
+func startMQTT(t *testing.T) *testBroker {
+	b := &testBroker{}
+	b.addr = listen(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		for {
+			header, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			length, err := binary.ReadUvarint(r)
+			if err != nil {
+				return
+			}
+			packet := make([]byte, length)
+			if _, err := io.ReadFull(r, packet); err != nil {
+				return
+			}
+
+			switch header >> 4 {
+			case 1: // CONNECT
+				conn.Write([]byte{0x20, 2, 0, 0})
+			case 3: // PUBLISH
+				n := int(binary.BigEndian.Uint16(packet))
+				topic, rest := string(packet[2:2+n]), packet[2+n:]
+				if qos := header >> 1 & 3; qos > 0 {
+					conn.Write([]byte{0x40, 2, rest[0], rest[1]})
+					rest = rest[2:]
+				}
+				b.add(topic, rest)
+			case 12: // PINGREQ
+				conn.Write([]byte{0xd0, 0})
+			case 14: // DISCONNECT
+				return
+			}
+		}
+	})
+	return b
+}
+
+func testTrade() types.MarketTrade {
+	return types.MarketTrade{
+		Market:     testMarket(),
+		AssetID:    "222",
+		Side:       "sell",
+		Price:      0.58,
+		Size:       100,
+		UsdValue:   58,
+		Timestamp:  "1767225601000",
+		ReceivedAt: time.Date(2026, 1, 1, 0, 0, 1, 500e6, time.UTC),
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// checkPublished compares what a broker received with the payloads the sink
+// was given, keyed by topic. Message payloads are stamped with the send
+// time, so only their other fields are compared.
+func checkPublished(t *testing.T, got map[string][]byte, detections, trades, messages string, d types.DetectedTrade, tr types.MarketTrade, m Message) {
+	t.Helper()
+	if len(got) != 3 {
+		t.Errorf("received %d topics, want 3: %v", len(got), keys(got))
+	}
+
+	want, _ := json.Marshal(NewPayload(d))
+	if body, ok := got[detections]; !ok {
+		t.Errorf("nothing published to %s; got %v", detections, keys(got))
+	} else if string(body) != string(want) {
+		t.Errorf("detection payload:\n got %s\nwant %s", body, want)
+	}
+
+	want, _ = json.Marshal(NewTradePayload(tr))
+	if body, ok := got[trades]; !ok {
+		t.Errorf("nothing published to %s; got %v", trades, keys(got))
+	} else if string(body) != string(want) {
+		t.Errorf("trade payload:\n got %s\nwant %s", body, want)
+	}
+
+	body, ok := got[messages]
+	if !ok {
+		t.Errorf("nothing published to %s; got %v", messages, keys(got))
+		return
+	}
+	var mp MessagePayload
+	if err := json.Unmarshal(body, &mp); err != nil {
+		t.Fatalf("message payload: %v", err)
+	}
+	if mp.Version != PayloadVersion || mp.Type != m.Kind || mp.Title != m.Title || mp.Text != m.Text || mp.Severity != m.Severity || mp.SentAt.IsZero() {
+		t.Errorf("message payload = %s", body)
+	}
+}
+
+func keys(m map[string][]byte) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func TestTopicToken(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"us-election-2028", "us-election-2028"},
+		{"", "unknown"},
+		{"fed.rates 2026", "fed_rates_2026"},
+		{"a/b+c#d*e>f", "a_b_c_d_e_f"},
+	}
+	for _, tt := range tests {
+		if got := topicToken(tt.in); got != tt.want {
+			t.Errorf("topicToken(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNATSSinkPublishes(t *testing.T) {
+	broker := startNATS(t)
+	s, err := newNATSSink("nats", "nats://"+broker.addr, "", "", "alerts", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "connection", s.conn.IsConnected)
+
+	d, tr := testDetection(), testTrade()
+	m := Message{Kind: KindTest, Title: "Test", Text: "hello", Severity: types.SeverityInfo}
+	if err := s.Send(d); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PublishTrade(tr); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	checkPublished(t, broker.received(),
+		"alerts.detections.fed_rates_2026",
+		"alerts.trades.fed_rates_2026",
+		"alerts.messages.test",
+		d, tr, m)
+}
+
+func TestNATSSinkSkipsTradesUnlessEnabled(t *testing.T) {
+	broker := startNATS(t)
+	s, err := newNATSSink("nats", "nats://"+broker.addr, "", "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "connection", s.conn.IsConnected)
+
+	if err := s.PublishTrade(testTrade()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(testDetection()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := broker.received()
+	if len(got) != 1 || got["polymarket.detections.fed_rates_2026"] == nil {
+		t.Errorf("received %v, want only polymarket.detections.fed_rates_2026", keys(got))
+	}
+}
+
+func TestMQTTSinkPublishes(t *testing.T) {
+	broker := startMQTT(t)
+	s := newMQTTSink("mqtt", "tcp://"+broker.addr, "", "", "alerts", true)
+	waitFor(t, "connection", s.client.IsConnectionOpen)
+
+	d, tr := testDetection(), testTrade()
+	m := Message{Kind: KindTest, Title: "Test", Text: "hello", Severity: types.SeverityInfo}
+	if err := s.Send(d); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PublishTrade(tr); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SendMessage(m); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Trades go out at QoS 0, which close does not wait for.
+	waitFor(t, "three publishes", func() bool { return len(broker.received()) >= 3 })
+
+	checkPublished(t, broker.received(),
+		"alerts/detections/fed_rates_2026",
+		"alerts/trades/fed_rates_2026",
+		"alerts/messages/test",
+		d, tr, m)
+}
+
+func TestMQTTSinkProbeWaitsForAck(t *testing.T) {
+	broker := startMQTT(t)
+	s := newMQTTSink("mqtt", "tcp://"+broker.addr, "", "", "", false)
+	defer s.client.Disconnect(0)
+	waitFor(t, "connection", s.client.IsConnectionOpen)
+
+	if err := s.probe(Message{Kind: KindTest, Title: "Test"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := broker.received(); got["polymarket/messages/test"] == nil {
+		t.Errorf("received %v, want polymarket/messages/test", keys(got))
+	}
+}
@@ -0,0 +1,282 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const digestTopTrades = 5
+
+// digestSink wraps a sink so that detections are batched and delivered as a
+// single summary message every interval. Critical detections and messages
+// pass straight through.
+type digestSink struct {
+	Sink
+	interval time.Duration
+	done     chan struct{} // closed by stop
+	stopped  chan struct{} // closed when run returns
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	start   time.Time
+	pending []types.DetectedTrade
+}
+
+func newDigestSink(sink Sink, interval time.Duration) *digestSink {
+	s := &digestSink{Sink: sink, interval: interval, done: make(chan struct{}), stopped: make(chan struct{}), start: time.Now()}
+	go s.run()
+	return s
+}
+
+func (s *digestSink) Send(d types.DetectedTrade) error {
+	if d.Severity == types.SeverityCritical {
+		return s.Sink.Send(d)
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, d)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *digestSink) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				notifierLog(s.Name()).Error("digest delivery failed", "err", err)
+			}
+		}
+	}
+}
+
+// stop ends the periodic flushes, waiting for one in progress. Detections
+// still pending are left for a final flush.
+func (s *digestSink) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+	<-s.stopped
+}
+
+// drain flushes the batch at shutdown. A batch quiet hours are holding back
+// is returned instead, for the caller to keep until the next run.
+func (s *digestSink) drain() (*types.HeldDigest, error) {
+	if q, ok := s.Sink.(*quietSink); !ok || !q.quiet(time.Now()) {
+		return nil, s.flush()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	held := &types.HeldDigest{Sink: s.Name(), Start: s.start, Detections: s.pending}
+	s.pending = nil
+	return held, nil
+}
+
+// restore puts back a batch held by a previous run, so the next digest
+// covers it.
+func (s *digestSink) restore(held types.HeldDigest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(held.Detections, s.pending...)
+	if held.Start.Before(s.start) {
+		s.start = held.Start
+	}
+}
+
+func (s *digestSink) flush() error {
+	now := time.Now()
+
+	// Hold the batch until the wrapped sink's quiet hours end so the first
+	// digest afterwards covers the whole quiet period.
+	if q, ok := s.Sink.(*quietSink); ok && q.quiet(now) {
+		return nil
+	}
+
+	s.mu.Lock()
+	pending := s.pending
+	start := s.start
+	s.pending = nil
+	s.start = now
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return s.Sink.SendMessage(buildDigest(pending, start, now).message())
+}
+
+// Digest summarizes the detections batched over one digest interval.
+type Digest struct {
+	Start     time.Time      `json:"start"`
+	End       time.Time      `json:"end"`
+	Count     int            `json:"count"`
+	UsdValue  float64        `json:"usdValue"`
+	Markets   []DigestMarket `json:"markets"`
+	TopTrades []Payload      `json:"topTrades"`
+	Flows     []DigestFlow   `json:"flows"`
+	Whales    []DigestWhale  `json:"whales"`
+}
+
+type DigestMarket struct {
+	Question string  `json:"question"`
+	Slug     string  `json:"slug"`
+	Count    int     `json:"count"`
+	UsdValue float64 `json:"usdValue"`
+}
+
+// DigestFlow is the net buy minus sell notional for one outcome of a market.
+type DigestFlow struct {
+	Question string  `json:"question"`
+	Outcome  string  `json:"outcome"`
+	BuyUSD   float64 `json:"buyUsd"`
+	SellUSD  float64 `json:"sellUsd"`
+	NetUSD   float64 `json:"netUsd"`
+}
+
+type DigestWhale struct {
+	Name     string  `json:"name"`
+	Count    int     `json:"count"`
+	UsdValue float64 `json:"usdValue"`
+}
+
+func buildDigest(detections []types.DetectedTrade, start, end time.Time) Digest {
+	dg := Digest{Start: start.UTC(), End: end.UTC(), Count: len(detections)}
+
+	markets := make(map[string]*DigestMarket)
+	flows := make(map[string]*DigestFlow)
+	whales := make(map[string]*DigestWhale)
+
+	for _, d := range detections {
+		dg.UsdValue += d.UsdValue
+
+		m := markets[d.Market.ConditionID]
+		if m == nil {
+			m = &DigestMarket{Question: d.Market.Question, Slug: d.Market.Slug}
+			markets[d.Market.ConditionID] = m
+		}
+		m.Count++
+		m.UsdValue += d.UsdValue
+
+		outcome := getOutcome(d.Market, d.AssetID)
+		key := d.Market.ConditionID + "|" + outcome
+		f := flows[key]
+		if f == nil {
+			f = &DigestFlow{Question: d.Market.Question, Outcome: outcome}
+			flows[key] = f
+		}
+		if strings.EqualFold(d.Side, "sell") {
+			f.SellUSD += d.UsdValue
+		} else {
+			f.BuyUSD += d.UsdValue
+		}
+		f.NetUSD = f.BuyUSD - f.SellUSD
+
+		if d.WhaleName != "" {
+			w := whales[d.WhaleName]
+			if w == nil {
+				w = &DigestWhale{Name: d.WhaleName}
+				whales[d.WhaleName] = w
+			}
+			w.Count++
+			w.UsdValue += d.UsdValue
+		}
+	}
+
+	for _, m := range markets {
+		dg.Markets = append(dg.Markets, *m)
+	}
+	sort.Slice(dg.Markets, func(i, j int) bool {
+		if dg.Markets[i].Count != dg.Markets[j].Count {
+			return dg.Markets[i].Count > dg.Markets[j].Count
+		}
+		return dg.Markets[i].UsdValue > dg.Markets[j].UsdValue
+	})
+
+	for _, f := range flows {
+		dg.Flows = append(dg.Flows, *f)
+	}
+	sort.Slice(dg.Flows, func(i, j int) bool {
+		return abs(dg.Flows[i].NetUSD) > abs(dg.Flows[j].NetUSD)
+	})
+
+	for _, w := range whales {
+		dg.Whales = append(dg.Whales, *w)
+	}
+	sort.Slice(dg.Whales, func(i, j int) bool {
+		return dg.Whales[i].UsdValue > dg.Whales[j].UsdValue
+	})
+
+	sorted := append([]types.DetectedTrade(nil), detections...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UsdValue > sorted[j].UsdValue
+	})
+	for i, d := range sorted {
+		if i >= digestTopTrades {
+			break
+		}
+		dg.TopTrades = append(dg.TopTrades, NewPayload(d))
+	}
+
+	return dg
+}
+
+func (dg Digest) message() Message {
+	var b strings.Builder
+
+	b.WriteString("By market:\n")
+	for _, m := range dg.Markets {
+		fmt.Fprintf(&b, "  %3d  %-10s %s\n", m.Count, formatUSD(m.UsdValue), truncate(60, m.Question))
+	}
+
+	b.WriteString("\nTop trades:\n")
+	for _, t := range dg.TopTrades {
+		who := ""
+		if t.Trader != "" {
+			who = " (" + t.Trader + ")"
+		}
+		fmt.Fprintf(&b, "  %-10s %-4s %s @ %.3f  %s%s\n", formatUSD(t.UsdValue), strings.ToUpper(t.Side), t.Outcome, t.Price, truncate(50, t.Question), who)
+	}
+
+	b.WriteString("\nNet flow:\n")
+	for _, f := range dg.Flows {
+		sign := "+"
+		if f.NetUSD < 0 {
+			sign = "-"
+		}
+		fmt.Fprintf(&b, "  %s%-10s %s  %s\n", sign, formatUSD(abs(f.NetUSD)), f.Outcome, truncate(50, f.Question))
+	}
+
+	if len(dg.Whales) > 0 {
+		b.WriteString("\nWhales:\n")
+		for _, w := range dg.Whales {
+			fmt.Fprintf(&b, "  %s: %d trades, %s\n", w.Name, w.Count, formatUSD(w.UsdValue))
+		}
+	}
+
+	return Message{
+		Kind:  "digest",
+		Title: fmt.Sprintf("Digest: %d detections in the last %s (%s total)", dg.Count, formatDuration(dg.End.Sub(dg.Start).Round(time.Second)), formatUSD(dg.UsdValue)),
+		Text:  strings.TrimRight(b.String(), "\n"),
+		Data:  dg,
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// discordSink posts a rich embed to a Discord (or Slack-compatible) webhook.
+// When a template is configured the embed is replaced by the rendered text.
+type discordSink struct {
+	name string
+	url  string
+	tmpl *template.Template
+	http *http.Client
+}
+
+func newDiscordSink(name, url, preset, path string, client *http.Client) (*discordSink, error) {
+	tmpl, err := loadTemplate(name, preset, path)
+	if err != nil {
+		return nil, err
+	}
+	return &discordSink{name: name, url: url, tmpl: tmpl, http: client}, nil
+}
+
+func (s *discordSink) Name() string { return s.name }
+
+func (s *discordSink) Send(d types.DetectedTrade) error {
+	payload, err := s.buildPayload(d)
+	if err != nil {
+		return err
+	}
+	return s.post(payload)
+}
+
+// SendMessage posts the message as plain content, split on line boundaries
+// into as many posts as Discord's 2000 character limit requires.
+func (s *discordSink) SendMessage(m Message) error {
+	for _, chunk := range chunkLines(fmt.Sprintf("**%s**\n%s", m.Title, m.Text), discordMaxContent) {
+		if err := s.post(map[string]interface{}{"content": chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const discordMaxContent = 2000
+
+func chunkLines(text string, max int) []string {
+	var chunks []string
+	var cur strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = truncate(max, line)
+		if cur.Len() > 0 && len([]rune(cur.String()))+1+len([]rune(line)) > max {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte('\n')
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+func (s *discordSink) post(payload map[string]interface{}) error {
+	body, _ := json.Marshal(payload)
+	resp, err := s.http.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *discordSink) buildPayload(d types.DetectedTrade) (map[string]interface{}, error) {
+	if s.tmpl != nil {
+		text, err := render(s.tmpl, d)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"content": string(text)}, nil
+	}
+
+	outcome := getOutcome(d.Market, d.AssetID)
+	ts := parseTimestamp(d.Timestamp)
+
+	color := 0x00ff00 // green for buy
+	if strings.ToLower(d.Side) == "sell" {
+		color = 0xff0000 // red for sell
+	}
+
+	payload := map[string]interface{}{
+		"content": "**🐋 FAT TRADE DETECTED**",
+		"embeds": []map[string]interface{}{
+			{
+				"title":     d.Market.Question,
+				"url":       fmt.Sprintf("https://polymarket.com/event/%s", d.Market.Slug),
+				"color":     color,
+				"fields":    buildWebhookFields(d, outcome),
+				"timestamp": ts.Format(time.RFC3339),
+			},
+		},
+	}
+	return payload, nil
+}
+
+func buildWebhookFields(d types.DetectedTrade, outcome string) []map[string]interface{} {
+	fields := []map[string]interface{}{
+		{"name": "Outcome", "value": outcome, "inline": true},
+		{"name": "Side", "value": strings.ToUpper(d.Side), "inline": true},
+		{"name": "Value", "value": fmt.Sprintf("$%.2f", d.UsdValue), "inline": true},
+		{"name": "Size", "value": fmt.Sprintf("%.2f", d.Size), "inline": true},
+		{"name": "Price", "value": fmt.Sprintf("%.4f", d.Price), "inline": true},
+	}
+	if d.Trader != "" {
+		fields = append(fields, map[string]interface{}{"name": "Trader", "value": d.Trader, "inline": true})
+	}
+	if d.Wallet != "" {
+		wallet := d.Wallet
+		if len(wallet) > 16 {
+			wallet = wallet[:16] + "..."
+		}
+		fields = append(fields, map[string]interface{}{"name": "Wallet", "value": wallet, "inline": true})
+	}
+	fields = append(fields, map[string]interface{}{"name": "Reason", "value": d.Reason, "inline": false})
+	return fields
+}
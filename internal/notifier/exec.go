@@ -0,0 +1,184 @@
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	defaultExecTimeout     = 10 * time.Second
+	defaultExecConcurrency = 4
+)
+
+// execSink runs a shell command for every detection or message. The JSON
+// payload is written to the command's stdin and the most useful fields are
+// exported as PM_* environment variables. Commands run in the background;
+// once concurrency commands are in flight Send blocks until one exits.
+type execSink struct {
+	name    string
+	command string
+	timeout time.Duration
+	slots   chan struct{}
+}
+
+func newExecSink(name, command string, timeout time.Duration, concurrency int) *execSink {
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	if concurrency <= 0 {
+		concurrency = defaultExecConcurrency
+	}
+	return &execSink{
+		name:    name,
+		command: command,
+		timeout: timeout,
+		slots:   make(chan struct{}, concurrency),
+	}
+}
+
+func (s *execSink) Name() string { return s.name }
+
+func (s *execSink) Send(d types.DetectedTrade) error {
+	p, env := detectionEnv(d)
+	return s.run(p, env)
+}
+
+// sendAndWait runs the command for d in the foreground and returns its error.
+func (s *execSink) sendAndWait(d types.DetectedTrade) error {
+	p, env := detectionEnv(d)
+	return s.runAndWait(p, env)
+}
+
+// probe runs the command for m in the foreground and returns its error.
+func (s *execSink) probe(m Message) error {
+	p, env := messageEnv(m)
+	return s.runAndWait(p, env)
+}
+
+func detectionEnv(d types.DetectedTrade) (Payload, []string) {
+	p := NewPayload(d)
+	return p, []string{
+		"PM_TYPE=" + p.Type,
+		"PM_CONDITION_ID=" + p.ConditionID,
+		"PM_MARKET_SLUG=" + p.MarketSlug,
+		"PM_EVENT_SLUG=" + p.EventSlug,
+		"PM_QUESTION=" + p.Question,
+		"PM_ASSET_ID=" + p.AssetID,
+		"PM_OUTCOME=" + p.Outcome,
+		"PM_SIDE=" + p.Side,
+		"PM_PRICE=" + strconv.FormatFloat(p.Price, 'f', -1, 64),
+		"PM_SIZE=" + strconv.FormatFloat(p.Size, 'f', -1, 64),
+		"PM_USD_VALUE=" + strconv.FormatFloat(p.UsdValue, 'f', 2, 64),
+		"PM_REASON=" + p.Reason,
+		"PM_REASON_TYPES=" + strings.Join(p.ReasonTypes, ","),
+		"PM_SEVERITY=" + p.Severity,
+		"PM_WALLET=" + p.Wallet,
+		"PM_TRADER=" + p.Trader,
+		"PM_WHALE=" + p.WhaleName,
+		"PM_URL=" + p.URL,
+		"PM_TRADE_TIME=" + p.TradeTime.Format(time.RFC3339),
+	}
+}
+
+// SendMessage runs the command for summaries, digests and reports. PM_TYPE
+// is the message kind, e.g. "digest".
+func (s *execSink) SendMessage(m Message) error {
+	p, env := messageEnv(m)
+	return s.run(p, env)
+}
+
+func messageEnv(m Message) (MessagePayload, []string) {
+	p := NewMessagePayload(m)
+	return p, []string{
+		"PM_TYPE=" + p.Type,
+		"PM_TITLE=" + p.Title,
+		"PM_SEVERITY=" + p.Severity,
+	}
+}
+
+func (s *execSink) run(payload interface{}, env []string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	s.slots <- struct{}{}
+	go func() {
+		defer func() { <-s.slots }()
+		if err := s.exec(body, env); err != nil {
+			notifierLog(s.name).Error("hook failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (s *execSink) runAndWait(payload interface{}, env []string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+	return s.exec(body, env)
+}
+
+// close waits for commands in flight by taking every slot, then gives the
+// slots back.
+func (s *execSink) close(ctx context.Context) error {
+	taken := 0
+	defer func() {
+		for ; taken > 0; taken-- {
+			<-s.slots
+		}
+	}()
+
+	for taken < cap(s.slots) {
+		select {
+		case s.slots <- struct{}{}:
+			taken++
+		case <-ctx.Done():
+			return fmt.Errorf("%d hooks still running: %w", cap(s.slots)-taken, ctx.Err())
+		}
+	}
+	return nil
+}
+
+func (s *execSink) exec(stdin []byte, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), env...)
+	// Don't wait forever on grandchildren still holding stderr after the
+	// shell itself was killed.
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+
+	scanner := bufio.NewScanner(&stderr)
+	for scanner.Scan() {
+		notifierLog(s.name).Warn("hook stderr", "line", scanner.Text())
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", s.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("command failed: %w", err)
+	}
+	return nil
+}
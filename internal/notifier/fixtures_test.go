@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// testMarket is a two-outcome market whose event slug contains characters
+// that are not allowed in topic levels or subject tokens.
+func testMarket() *types.Market {
+	return &types.Market{
+		ID:          "512",
+		ConditionID: "0xabc123",
+		Question:    "Will the Fed cut rates in March?",
+		Slug:        "fed-cut-march",
+		Outcomes:    `["Yes","No"]`,
+		ClobTokens:  `["111","222"]`,
+		Events:      []types.MarketEvent{{Slug: "fed.rates 2026", Title: "Fed rates"}},
+	}
+}
+
+// testDetection is a large buy with a fixed trade and detection time, so its
+// payload is the same on every run.
+func testDetection() types.DetectedTrade {
+	return types.DetectedTrade{
+		Market:      testMarket(),
+		AssetID:     "111",
+		Side:        "buy",
+		Price:       0.42,
+		Size:        11904.76,
+		UsdValue:    5000,
+		Timestamp:   "1767225600000",
+		Reason:      "Large trade: $5.0K",
+		Reasons:     []string{"Large trade: $5.0K"},
+		ReasonTypes: []string{types.ReasonLarge},
+		Severity:    types.SeverityWarning,
+		Wallet:      "0x1234567890abcdef1234567890abcdef12345678",
+		DetectedAt:  time.Date(2026, 1, 1, 0, 0, 2, 0, time.UTC),
+	}
+}
+
+// recordingSink keeps what it is sent.
+type recordingSink struct {
+	name string
+
+	mu         sync.Mutex
+	detections []types.DetectedTrade
+	messages   []Message
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(d types.DetectedTrade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.detections = append(s.detections, d)
+	return nil
+}
+
+func (s *recordingSink) SendMessage(m Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	return nil
+}
+
+func (s *recordingSink) counts() (detections, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.detections), len(s.messages)
+}
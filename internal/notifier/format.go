@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// TemplateData is what console and sink templates are executed against: the
+// stable Payload fields plus the full market for lookups.
+type TemplateData struct {
+	Payload
+	Market *types.Market
+}
+
+func newTemplateData(d types.DetectedTrade) TemplateData {
+	return TemplateData{Payload: NewPayload(d), Market: d.Market}
+}
+
+// Presets are the built-in templates selectable by name via *_FORMAT.
+var Presets = map[string]string{
+	"verbose": `
+============================================================
+🐋 FAT TRADE DETECTED
+Market: {{.Question}}
+Outcome: {{.Outcome}}
+Side: {{upper .Side}}
+Size: {{printf "%.2f" .Size}} @ {{printf "%.4f" .Price}}
+Value: ${{printf "%.2f" .UsdValue}}
+{{if .Trader}}Trader: {{.Trader}}
+{{end}}{{if .Wallet}}Wallet: {{.Wallet}}
+{{end}}Reason: {{.Reason}}
+URL: {{.URL}}
+Time: {{rfc3339 .TradeTime}}
+============================================================
+
+`,
+	"compact": `{{rfc3339 .TradeTime}} {{upper .Side}} {{usd .UsdValue}} {{.Outcome}} @ {{printf "%.3f" .Price}} | {{truncate 60 .Question}}{{if .Trader}} | {{.Trader}}{{end}} | {{.Reason}}
+`,
+	"ndjson": `{{json .Payload}}
+`,
+}
+
+var templateFuncs = template.FuncMap{
+	"usd":      formatUSD,
+	"ago":      formatTimeAgo,
+	"outcome":  getOutcome,
+	"truncate": truncate,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"join":     strings.Join,
+	"json":     toJSON,
+	"rfc3339":  func(t time.Time) string { return t.Format(time.RFC3339) },
+}
+
+// loadTemplate resolves a template from a file path, falling back to a named
+// preset. It returns nil when neither is set so callers can use their default.
+func loadTemplate(name, preset, path string) (*template.Template, error) {
+	var text string
+	switch {
+	case path != "":
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s template: %w", name, err)
+		}
+		text = string(b)
+	case preset != "":
+		t, ok := Presets[preset]
+		if !ok {
+			return nil, fmt.Errorf("%s format: unknown preset %q", name, preset)
+		}
+		text = t
+	default:
+		return nil, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func render(tmpl *template.Template, d types.DetectedTrade) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newTemplateData(d)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatUSD(value float64) string {
+	if value >= 1_000_000 {
+		return fmt.Sprintf("$%.2fM", value/1_000_000)
+	}
+	if value >= 1_000 {
+		return fmt.Sprintf("$%.1fK", value/1_000)
+	}
+	return fmt.Sprintf("$%.2f", value)
+}
+
+func formatTimeAgo(t time.Time) string {
+	diff := time.Since(t)
+
+	switch {
+	case diff < time.Minute:
+		return "just now"
+	case diff < time.Hour:
+		return fmt.Sprintf("%dm ago", int(diff.Minutes()))
+	case diff < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(diff.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(diff.Hours()/24))
+	}
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func truncate(n int, s string) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,393 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	pagerDutyURL        = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieURL         = "https://api.opsgenie.com"
+	defaultResolveAfter = 30 * time.Minute
+	incidentSource      = "polymarket-tool"
+)
+
+// incidentSink is implemented by sinks that page someone. The router leaves
+// them out of the default fan-out, so they only receive detections from
+// routes or a default that name them.
+type incidentSink interface {
+	incident()
+}
+
+// parseMinSeverity validates an incident sink's minimum severity, which
+// defaults to critical.
+func parseMinSeverity(s string) (string, error) {
+	switch s {
+	case "":
+		return types.SeverityCritical, nil
+	case types.SeverityInfo, types.SeverityWarning, types.SeverityCritical:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid minSeverity %q (%s, %s, %s)", s, types.SeverityInfo, types.SeverityWarning, types.SeverityCritical)
+}
+
+// incidentKey groups detections into one incident per market side, so
+// repeated detections update the open incident rather than opening new ones.
+func incidentKey(d types.DetectedTrade) string {
+	return incidentSource + ":" + d.Market.ConditionID + ":" + strings.ToLower(d.Side)
+}
+
+// resolver closes incidents once no detection has refreshed them for the
+// quiet period.
+type resolver struct {
+	after   time.Duration
+	resolve func(key string) error
+	name    string
+
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func newResolver(name string, after time.Duration, resolve func(key string) error) *resolver {
+	if after <= 0 {
+		after = defaultResolveAfter
+	}
+	return &resolver{name: name, after: after, resolve: resolve, timers: make(map[string]*time.Timer)}
+}
+
+func (r *resolver) touch(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.timers[key]; ok {
+		t.Reset(r.after)
+		return
+	}
+	r.timers[key] = time.AfterFunc(r.after, func() {
+		r.mu.Lock()
+		delete(r.timers, key)
+		r.mu.Unlock()
+
+		if err := r.resolve(key); err != nil {
+			notifierLog(r.name).Error("resolve failed", "err", err)
+		}
+	})
+}
+
+// resolveAll stops the timers and resolves every incident still open, so a
+// shutdown doesn't leave them open with nothing left to resolve them. It
+// gives up when ctx ends.
+func (r *resolver) resolveAll(ctx context.Context) error {
+	r.mu.Lock()
+	var keys []string
+	for key, t := range r.timers {
+		// A timer that already fired is resolving its incident itself.
+		if t.Stop() {
+			keys = append(keys, key)
+		}
+	}
+	r.timers = make(map[string]*time.Timer)
+	r.mu.Unlock()
+
+	var errs []error
+	for i, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, fmt.Errorf("%d incidents left open: %w", len(keys)-i, err))...)
+		}
+		if err := r.resolve(key); err != nil {
+			errs = append(errs, fmt.Errorf("resolve %s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pagerDutySink triggers PagerDuty Events API v2 alerts.
+type pagerDutySink struct {
+	name        string
+	url         string
+	routingKey  string
+	minSeverity string
+	http        *http.Client
+	resolver    *resolver
+}
+
+func newPagerDutySink(name, endpoint, routingKey, minSeverity string, resolveAfter time.Duration, client *http.Client) (*pagerDutySink, error) {
+	minSeverity, err := parseMinSeverity(minSeverity)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == "" {
+		endpoint = pagerDutyURL
+	}
+	s := &pagerDutySink{name: name, url: endpoint, routingKey: routingKey, minSeverity: minSeverity, http: client}
+	s.resolver = newResolver(name, resolveAfter, s.resolve)
+	return s, nil
+}
+
+func (s *pagerDutySink) Name() string { return s.name }
+
+func (s *pagerDutySink) incident() {}
+
+// Send pages for detections at or above the sink's minimum severity.
+func (s *pagerDutySink) Send(d types.DetectedTrade) error {
+	if types.SeverityRank(d.Severity) < types.SeverityRank(s.minSeverity) {
+		return nil
+	}
+	p := NewPayload(d)
+	key := incidentKey(d)
+
+	err := s.post(map[string]interface{}{
+		"routing_key":  s.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    key,
+		"payload": map[string]interface{}{
+			"summary":        truncate(1024, fmt.Sprintf("%s %s %s on %s: %s", formatUSD(p.UsdValue), strings.ToUpper(p.Side), p.Outcome, p.Question, p.Reason)),
+			"source":         incidentSource,
+			"severity":       pagerDutySeverity(p.Severity),
+			"timestamp":      p.TradeTime.Format(time.RFC3339),
+			"component":      p.EventSlug,
+			"class":          strings.Join(p.ReasonTypes, ","),
+			"custom_details": p,
+		},
+		"links": []map[string]string{{"href": p.URL, "text": "Polymarket"}},
+	})
+	if err == nil {
+		s.resolver.touch(key)
+	}
+	return err
+}
+
+// SendMessage only pages for critical messages; summaries, digests and
+// reports are not incidents. Health incidents stay open until the recovery
+// message rather than resolving after the quiet period.
+func (s *pagerDutySink) SendMessage(m Message) error {
+	key := incidentSource + ":" + m.Kind
+	if m.Kind == KindHealth && m.Severity != types.SeverityCritical {
+		return s.resolve(key)
+	}
+	if m.Severity != types.SeverityCritical {
+		return nil
+	}
+	err := s.post(map[string]interface{}{
+		"routing_key":  s.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    key,
+		"payload": map[string]interface{}{
+			"summary":        truncate(1024, m.Title),
+			"source":         incidentSource,
+			"severity":       "critical",
+			"custom_details": map[string]interface{}{"text": m.Text, "data": m.Data},
+		},
+	})
+	if err == nil && m.Kind != KindHealth {
+		s.resolver.touch(key)
+	}
+	return err
+}
+
+// probe resolves an incident that was never opened, which PagerDuty accepts
+// only with a valid routing key, so the key is checked without paging.
+func (s *pagerDutySink) probe(Message) error {
+	return s.resolve(incidentSource + ":" + KindTest)
+}
+
+// close resolves the incidents this process opened.
+func (s *pagerDutySink) close(ctx context.Context) error {
+	return s.resolver.resolveAll(ctx)
+}
+
+func (s *pagerDutySink) resolve(key string) error {
+	return s.post(map[string]interface{}{
+		"routing_key":  s.routingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
+
+func (s *pagerDutySink) post(event map[string]interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := s.http.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case types.SeverityCritical:
+		return "critical"
+	case types.SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// opsgenieSink creates Opsgenie alerts, relying on the alias to deduplicate
+// repeats into the open alert.
+type opsgenieSink struct {
+	name        string
+	baseURL     string
+	apiKey      string
+	minSeverity string
+	http        *http.Client
+	resolver    *resolver
+}
+
+func newOpsgenieSink(name, baseURL, apiKey, minSeverity string, resolveAfter time.Duration, client *http.Client) (*opsgenieSink, error) {
+	minSeverity, err := parseMinSeverity(minSeverity)
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		baseURL = opsgenieURL
+	}
+	s := &opsgenieSink{name: name, baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, minSeverity: minSeverity, http: client}
+	s.resolver = newResolver(name, resolveAfter, s.closeAlert)
+	return s, nil
+}
+
+func (s *opsgenieSink) Name() string { return s.name }
+
+func (s *opsgenieSink) incident() {}
+
+// Send alerts for detections at or above the sink's minimum severity.
+func (s *opsgenieSink) Send(d types.DetectedTrade) error {
+	if types.SeverityRank(d.Severity) < types.SeverityRank(s.minSeverity) {
+		return nil
+	}
+	p := NewPayload(d)
+	key := incidentKey(d)
+
+	details := map[string]string{
+		"question":  p.Question,
+		"outcome":   p.Outcome,
+		"side":      p.Side,
+		"price":     fmt.Sprintf("%.4f", p.Price),
+		"size":      fmt.Sprintf("%.2f", p.Size),
+		"usdValue":  fmt.Sprintf("%.2f", p.UsdValue),
+		"reason":    p.Reason,
+		"url":       p.URL,
+		"eventSlug": p.EventSlug,
+	}
+	if p.Wallet != "" {
+		details["wallet"] = p.Wallet
+	}
+	if p.Trader != "" {
+		details["trader"] = p.Trader
+	}
+
+	err := s.post("/v2/alerts", map[string]interface{}{
+		"message":     truncate(130, fmt.Sprintf("%s %s %s on %s", formatUSD(p.UsdValue), strings.ToUpper(p.Side), p.Outcome, p.Question)),
+		"alias":       key,
+		"description": fmt.Sprintf("%s\n%s", p.Reason, p.URL),
+		"priority":    opsgeniePriority(p.Severity),
+		"source":      incidentSource,
+		"tags":        append([]string{"polymarket", p.Severity}, p.ReasonTypes...),
+		"details":     details,
+	})
+	if err == nil {
+		s.resolver.touch(key)
+	}
+	return err
+}
+
+// SendMessage only alerts for critical messages, like pagerDutySink.
+func (s *opsgenieSink) SendMessage(m Message) error {
+	key := incidentSource + ":" + m.Kind
+	if m.Kind == KindHealth && m.Severity != types.SeverityCritical {
+		return s.closeWithNote(key, m.Title)
+	}
+	if m.Severity != types.SeverityCritical {
+		return nil
+	}
+	err := s.post("/v2/alerts", map[string]interface{}{
+		"message":     truncate(130, m.Title),
+		"alias":       key,
+		"description": truncate(15000, m.Text),
+		"priority":    "P1",
+		"source":      incidentSource,
+		"tags":        []string{"polymarket", m.Kind},
+	})
+	if err == nil && m.Kind != KindHealth {
+		s.resolver.touch(key)
+	}
+	return err
+}
+
+// probe closes an alert that was never opened, which Opsgenie accepts only
+// with a valid API key, so the key is checked without alerting anyone.
+func (s *opsgenieSink) probe(Message) error {
+	return s.closeWithNote(incidentSource+":"+KindTest, "polymarket-tool configuration check")
+}
+
+// close closes the alerts this process opened.
+func (s *opsgenieSink) close(ctx context.Context) error {
+	return s.resolver.resolveAll(ctx)
+}
+
+func (s *opsgenieSink) closeAlert(key string) error {
+	return s.closeWithNote(key, "No further detections during the quiet period")
+}
+
+func (s *opsgenieSink) closeWithNote(key, note string) error {
+	path := fmt.Sprintf("/v2/alerts/%s/close?identifierType=alias", url.PathEscape(key))
+	return s.post(path, map[string]interface{}{
+		"source": incidentSource,
+		"note":   note,
+	})
+}
+
+func (s *opsgenieSink) post(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+s.apiKey)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case types.SeverityCritical:
+		return "P1"
+	case types.SeverityWarning:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// KindHealth marks tracker health messages. A critical one reports the
+// tracker unhealthy and anything else that it recovered, which incident sinks
+// use to open and resolve a single incident.
+const KindHealth = "health"
+
+// KindTest marks test messages used to check that a sink is set up.
+const KindTest = "test"
+
+// Message is a free-form notification that is not a single detection, such
+// as a summary of suppressed alerts.
+type Message struct {
+	Kind     string
+	Title    string
+	Text     string
+	Severity string
+	Data     interface{}
+}
+
+// MessagePayload is the JSON representation of a Message for machine-facing
+// sinks, versioned alongside Payload.
+type MessagePayload struct {
+	Version  int         `json:"version"`
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Text     string      `json:"text"`
+	Severity string      `json:"severity"`
+	Data     interface{} `json:"data,omitempty"`
+	SentAt   time.Time   `json:"sentAt"`
+}
+
+func NewMessagePayload(m Message) MessagePayload {
+	severity := m.Severity
+	if severity == "" {
+		severity = types.SeverityInfo
+	}
+	return MessagePayload{
+		Version:  PayloadVersion,
+		Type:     m.Kind,
+		Title:    m.Title,
+		Text:     m.Text,
+		Severity: severity,
+		Data:     m.Data,
+		SentAt:   time.Now().UTC(),
+	}
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// meteredSink counts deliveries and failures of the sink it wraps. It sits
+// directly around the base sink so batched digests count once, when sent.
+type meteredSink struct {
+	Sink
+}
+
+func metered(s Sink) Sink {
+	return &meteredSink{Sink: s}
+}
+
+// Send also records the trade-to-delivery latency of detections the sink
+// accepted.
+func (m *meteredSink) Send(d types.DetectedTrade) error {
+	err := m.observe(m.Sink.Send(d))
+	if ms, perr := strconv.ParseInt(d.Timestamp, 10, 64); err == nil && perr == nil {
+		metrics.NotificationLatency.WithLabelValues(m.Name()).Observe(time.Since(time.UnixMilli(ms)).Seconds())
+	}
+	return err
+}
+
+func (m *meteredSink) SendMessage(msg Message) error {
+	return m.observe(m.Sink.SendMessage(msg))
+}
+
+func (m *meteredSink) observe(err error) error {
+	if err != nil {
+		metrics.NotifierFailures.WithLabelValues(m.Name()).Inc()
+	} else {
+		metrics.NotifierDeliveries.WithLabelValues(m.Name()).Inc()
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// SetMutes replaces the active mutes. It is safe to call while detections
+// are being delivered.
+func (n *Notifier) SetMutes(mutes []types.Mute) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.mutes = mutes
+}
+
+// Muted reports whether an unexpired mute covers the detection's market or
+// wallet.
+func (n *Notifier) Muted(d types.DetectedTrade) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	now := time.Now()
+	for _, m := range n.mutes {
+		if !m.Expired(now) && m.Covers(d) {
+			return true
+		}
+	}
+	return false
+}
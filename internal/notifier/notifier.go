@@ -1,119 +1,545 @@
 package notifier
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
-	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
 	"github.com/mikefdy/polymarket-tool/internal/types"
 )
 
+// Sink delivers detections and messages to a single destination.
+type Sink interface {
+	Name() string
+	Send(d types.DetectedTrade) error
+	SendMessage(m Message) error
+}
+
 type Notifier struct {
-	cfg  *config.Config
-	http *http.Client
+	cfg        *config.Config
+	http       *http.Client
+	router     *router
+	publishers []TradePublisher
+	cooldowns  *cooldowns
+	mutes      []types.Mute
+	held       []types.HeldDigest
+	mu         sync.RWMutex
 }
 
-func New(cfg *config.Config) *Notifier {
-	return &Notifier{
+// New builds the sinks configured through the environment (named console,
+// discord, webhook, pagerduty, opsgenie, mqtt, nats and exec) plus any
+// declared in nc, and the routes between them. nc may be nil.
+func New(cfg *config.Config, nc *types.NotifyConfig) (*Notifier, error) {
+	n := &Notifier{
 		cfg:  cfg,
 		http: &http.Client{Timeout: 10 * time.Second},
 	}
+
+	var sinks []Sink
+
+	console, err := newConsoleSink("console", cfg.ConsoleFormat, cfg.ConsoleTemplate)
+	if err != nil {
+		return nil, err
+	}
+	sinks = append(sinks, console)
+
+	if cfg.WebhookURL != "" {
+		sink, err := newDiscordSink("discord", cfg.WebhookURL, cfg.WebhookFormat, cfg.WebhookTemplate, n.http)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.JSONWebhookURL != "" {
+		sink, err := newJSONWebhookSink("webhook", cfg.JSONWebhookURL, cfg.JSONWebhookSecret, cfg.JSONWebhookTemplate, n.http)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.PagerDutyRoutingKey != "" {
+		sink, err := newPagerDutySink("pagerduty", cfg.PagerDutyURL, cfg.PagerDutyRoutingKey, cfg.IncidentMinSeverity, cfg.ResolveAfter, n.http)
+		if err != nil {
+			return nil, fmt.Errorf("INCIDENT_MIN_SEVERITY: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.OpsgenieAPIKey != "" {
+		sink, err := newOpsgenieSink("opsgenie", cfg.OpsgenieURL, cfg.OpsgenieAPIKey, cfg.IncidentMinSeverity, cfg.ResolveAfter, n.http)
+		if err != nil {
+			return nil, fmt.Errorf("INCIDENT_MIN_SEVERITY: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.MQTTURL != "" {
+		sinks = append(sinks, newMQTTSink("mqtt", cfg.MQTTURL, cfg.MQTTUsername, cfg.MQTTPassword, cfg.MQTTPrefix, cfg.MQTTTrades))
+	}
+
+	if cfg.NATSURL != "" {
+		sink, err := newNATSSink("nats", cfg.NATSURL, "", "", cfg.NATSPrefix, cfg.NATSTrades)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if cfg.ExecCommand != "" {
+		sinks = append(sinks, newExecSink("exec", cfg.ExecCommand, cfg.ExecTimeout, cfg.ExecConcurrency))
+	}
+
+	for i := range sinks {
+		sinks[i] = metered(sinks[i])
+	}
+
+	var routes []types.Route
+	var fallback []string
+	if nc != nil {
+		for _, sc := range nc.Sinks {
+			sink, err := n.newSink(sc)
+			if err != nil {
+				return nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+			}
+			sinks = append(sinks, sink)
+		}
+		routes = nc.Routes
+		fallback = nc.Default
+	}
+
+	r, err := newRouter(sinks, routes, fallback)
+	if err != nil {
+		return nil, err
+	}
+	n.router = r
+
+	for _, s := range sinks {
+		if p, ok := unwrap(s).(TradePublisher); ok {
+			n.publishers = append(n.publishers, p)
+		}
+	}
+	n.cooldowns = newCooldowns(cfg.MarketCooldown, cfg.WalletCooldown, n.sendSummary)
+
+	return n, nil
+}
+
+// newSink builds a sink from its config and layers on quiet hours and
+// digesting. Digests wrap quiet hours so detections keep being batched
+// overnight and the summary is delivered once quiet hours end.
+func (n *Notifier) newSink(sc types.SinkConfig) (Sink, error) {
+	sink, err := n.newBaseSink(sc)
+	if err != nil {
+		return nil, err
+	}
+	sink = metered(sink)
+
+	if sc.QuietHours != "" {
+		if sink, err = newQuietSink(sink, sc.QuietHours, sc.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	if sc.Digest != "" {
+		interval, err := time.ParseDuration(sc.Digest)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid digest interval %q", sc.Digest)
+		}
+		sink = newDigestSink(sink, interval)
+	}
+
+	return sink, nil
+}
+
+func (n *Notifier) newBaseSink(sc types.SinkConfig) (Sink, error) {
+	if sc.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	switch sc.Type {
+	case "console":
+		return newConsoleSink(sc.Name, sc.Format, sc.Template)
+	case "discord":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("missing url")
+		}
+		return newDiscordSink(sc.Name, sc.URL, sc.Format, sc.Template, n.http)
+	case "webhook":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("missing url")
+		}
+		return newJSONWebhookSink(sc.Name, sc.URL, sc.Secret, sc.Template, n.http)
+	case "pagerduty":
+		if sc.RoutingKey == "" {
+			return nil, fmt.Errorf("missing routingKey")
+		}
+		resolveAfter, err := parseSinkDuration("resolveAfter", sc.ResolveAfter, n.cfg.ResolveAfter)
+		if err != nil {
+			return nil, err
+		}
+		return newPagerDutySink(sc.Name, sc.URL, sc.RoutingKey, sc.MinSeverity, resolveAfter, n.http)
+	case "opsgenie":
+		if sc.APIKey == "" {
+			return nil, fmt.Errorf("missing apiKey")
+		}
+		resolveAfter, err := parseSinkDuration("resolveAfter", sc.ResolveAfter, n.cfg.ResolveAfter)
+		if err != nil {
+			return nil, err
+		}
+		return newOpsgenieSink(sc.Name, sc.URL, sc.APIKey, sc.MinSeverity, resolveAfter, n.http)
+	case "mqtt":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("missing url")
+		}
+		return newMQTTSink(sc.Name, sc.URL, sc.Username, sc.Password, sc.Prefix, sc.Trades), nil
+	case "nats":
+		if sc.URL == "" {
+			return nil, fmt.Errorf("missing url")
+		}
+		return newNATSSink(sc.Name, sc.URL, sc.Username, sc.Password, sc.Prefix, sc.Trades)
+	case "exec":
+		if sc.Command == "" {
+			return nil, fmt.Errorf("missing command")
+		}
+		timeout, err := parseSinkDuration("timeout", sc.Timeout, n.cfg.ExecTimeout)
+		if err != nil {
+			return nil, err
+		}
+		concurrency := sc.Concurrency
+		if concurrency == 0 {
+			concurrency = n.cfg.ExecConcurrency
+		}
+		return newExecSink(sc.Name, sc.Command, timeout, concurrency), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", sc.Type)
+	}
+}
+
+// notifierLog returns the notifier logger for sink.
+func notifierLog(sink string) *slog.Logger {
+	return logging.For("notifier").With("sink", sink)
+}
+
+// unwrap returns the sink underneath any quiet hours or digest wrappers.
+func unwrap(s Sink) Sink {
+	for {
+		switch w := s.(type) {
+		case *quietSink:
+			s = w.Sink
+		case *digestSink:
+			s = w.Sink
+		case *meteredSink:
+			s = w.Sink
+		default:
+			return s
+		}
+	}
 }
 
-func (n *Notifier) Notify(detection types.DetectedTrade) {
-	n.printConsole(detection)
+func parseSinkDuration(field, s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", field, s)
+	}
+	return d, nil
+}
+
+// prober is implemented by sinks that need more than SendMessage to show
+// they work: brokers and exec hooks, which deliver in the background, and
+// incident sinks, for which any message would page someone.
+type prober interface {
+	probe(m Message) error
+}
 
-	if n.cfg.WebhookURL != "" {
-		n.sendWebhook(detection)
+// waitingSender is implemented by sinks whose Send returns before delivery,
+// to send and wait for the outcome.
+type waitingSender interface {
+	sendAndWait(d types.DetectedTrade) error
+}
+
+// Test sends m straight to the named sink, bypassing routing, quiet hours and
+// digests, so a misconfigured sink fails immediately. Brokers wait for the
+// publish to be acknowledged, and incident sinks check their credentials
+// instead of opening an incident.
+func (n *Notifier) Test(name string, m Message) error {
+	s, ok := n.router.byName[name]
+	if !ok {
+		return fmt.Errorf("unknown sink %q", name)
 	}
+
+	base := unwrap(s)
+	return deliver(context.Background(), s, m.Kind, func() error {
+		if p, ok := base.(prober); ok {
+			return p.probe(m)
+		}
+		return base.SendMessage(m)
+	})
 }
 
-func (n *Notifier) printConsole(d types.DetectedTrade) {
-	outcome := getOutcome(d.Market, d.AssetID)
-	ts := parseTimestamp(d.Timestamp)
+// Delivery is the outcome of sending a test detection to one sink.
+type Delivery struct {
+	Sink string
+	// Routed is false for sinks the routes would not send the detection
+	// to; nothing is sent to them.
+	Routed  bool
+	Err     error
+	Latency time.Duration
+}
 
-	fmt.Println()
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("🐋 FAT TRADE DETECTED")
-	fmt.Printf("Market: %s\n", d.Market.Question)
-	fmt.Printf("Outcome: %s\n", outcome)
-	fmt.Printf("Side: %s\n", strings.ToUpper(d.Side))
-	fmt.Printf("Size: %.2f @ %.4f\n", d.Size, d.Price)
-	fmt.Printf("Value: $%.2f\n", d.UsdValue)
-	if d.Trader != "" {
-		fmt.Printf("Trader: %s\n", d.Trader)
+// TestDetection sends d to the sinks routing picks for it, or to the named
+// sinks when names is set, and reports the outcome for every sink. Like Test
+// it bypasses mutes, cooldowns, quiet hours and digests.
+func (n *Notifier) TestDetection(d types.DetectedTrade, names []string) ([]Delivery, error) {
+	targets := make(map[string]bool)
+	for _, s := range n.router.route(d) {
+		targets[s.Name()] = true
+	}
+	if len(names) > 0 {
+		targets = make(map[string]bool)
+		for _, name := range names {
+			if _, ok := n.router.byName[name]; !ok {
+				return nil, fmt.Errorf("unknown sink %q", name)
+			}
+			targets[name] = true
+		}
 	}
-	if d.Wallet != "" {
-		fmt.Printf("Wallet: %s\n", d.Wallet)
+
+	deliveries := make([]Delivery, 0, len(n.router.all))
+	for _, s := range n.router.all {
+		result := Delivery{Sink: s.Name(), Routed: targets[s.Name()]}
+		if result.Routed {
+			base := unwrap(s)
+			start := time.Now()
+			result.Err = deliver(context.Background(), s, "detection", func() error {
+				if w, ok := base.(waitingSender); ok {
+					return w.sendAndWait(d)
+				}
+				return base.Send(d)
+			})
+			result.Latency = time.Since(start)
+		}
+		deliveries = append(deliveries, result)
 	}
-	fmt.Printf("Reason: %s\n", d.Reason)
-	fmt.Printf("URL: https://polymarket.com/event/%s\n", d.Market.Slug)
-	fmt.Printf("Time: %s\n", ts.Format(time.RFC3339))
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println()
+	return deliveries, nil
 }
 
-func (n *Notifier) sendWebhook(d types.DetectedTrade) {
-	outcome := getOutcome(d.Market, d.AssetID)
-	ts := parseTimestamp(d.Timestamp)
+// Sinks returns the names of every configured sink.
+func (n *Notifier) Sinks() []string {
+	names := make([]string, 0, len(n.router.all))
+	for _, s := range n.router.all {
+		names = append(names, s.Name())
+	}
+	return names
+}
 
-	color := 0x00ff00 // green for buy
-	if strings.ToLower(d.Side) == "sell" {
-		color = 0xff0000 // red for sell
+func (n *Notifier) Notify(ctx context.Context, detection types.DetectedTrade) {
+	if detection.Muted || n.Muted(detection) {
+		trace.SpanFromContext(ctx).AddEvent("muted")
+		return
+	}
+	if n.cooldowns != nil && !n.cooldowns.allow(detection) {
+		trace.SpanFromContext(ctx).AddEvent("suppressed by cooldown")
+		return
 	}
 
-	payload := map[string]interface{}{
-		"content": "**🐋 FAT TRADE DETECTED**",
-		"embeds": []map[string]interface{}{
-			{
-				"title": d.Market.Question,
-				"url":   fmt.Sprintf("https://polymarket.com/event/%s", d.Market.Slug),
-				"color": color,
-				"fields": buildWebhookFields(d, outcome),
-				"timestamp": ts.Format(time.RFC3339),
-			},
-		},
+	for _, s := range n.router.route(detection) {
+		err := deliver(ctx, s, "detection", func() error { return s.Send(detection) })
+		if err != nil {
+			notifierLog(s.Name()).Error("delivery failed", "err", err)
+		}
 	}
+}
+
+// deliver runs send inside a span for sink s.
+func deliver(ctx context.Context, s Sink, kind string, send func() error) error {
+	_, span := tracing.Tracer("notifier").Start(ctx, "notifier.deliver",
+		trace.WithAttributes(
+			attribute.String("notifier.sink", s.Name()),
+			attribute.String("notifier.kind", kind),
+		))
+	defer span.End()
 
-	body, _ := json.Marshal(payload)
-	resp, err := n.http.Post(n.cfg.WebhookURL, "application/json", bytes.NewReader(body))
+	err := send()
 	if err != nil {
-		log.Printf("[Webhook] Error: %v", err)
-		return
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
-	defer resp.Body.Close()
+	return err
+}
 
-	if resp.StatusCode >= 400 {
-		log.Printf("[Webhook] Failed: %d", resp.StatusCode)
+// PublishTrade forwards a raw trade to every sink publishing the trade tape.
+func (n *Notifier) PublishTrade(t types.MarketTrade) {
+	for _, p := range n.publishers {
+		if err := p.PublishTrade(t); err != nil {
+			notifierLog(p.(Sink).Name()).Error("trade publish failed", "err", err)
+		}
 	}
 }
 
-func buildWebhookFields(d types.DetectedTrade, outcome string) []map[string]interface{} {
-	fields := []map[string]interface{}{
-		{"name": "Outcome", "value": outcome, "inline": true},
-		{"name": "Side", "value": strings.ToUpper(d.Side), "inline": true},
-		{"name": "Value", "value": fmt.Sprintf("$%.2f", d.UsdValue), "inline": true},
-		{"name": "Size", "value": fmt.Sprintf("%.2f", d.Size), "inline": true},
-		{"name": "Price", "value": fmt.Sprintf("%.4f", d.Price), "inline": true},
+// SendMessage delivers m to the named sinks, or to every sink when names is
+// empty, bypassing routing.
+func (n *Notifier) SendMessage(m Message, names []string) error {
+	sinks := n.router.all
+	if len(names) > 0 {
+		sinks = make([]Sink, 0, len(names))
+		for _, name := range names {
+			s, ok := n.router.byName[name]
+			if !ok {
+				return fmt.Errorf("unknown sink %q", name)
+			}
+			sinks = append(sinks, s)
+		}
 	}
-	if d.Trader != "" {
-		fields = append(fields, map[string]interface{}{"name": "Trader", "value": d.Trader, "inline": true})
+
+	var errs []error
+	for _, s := range sinks {
+		err := deliver(context.Background(), s, m.Kind, func() error { return s.SendMessage(m) })
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
 	}
-	if d.Wallet != "" {
-		wallet := d.Wallet
-		if len(wallet) > 16 {
-			wallet = wallet[:16] + "..."
+	return errors.Join(errs...)
+}
+
+// closer is implemented by sinks holding work or connections that must be
+// flushed before exit.
+type closer interface {
+	close(ctx context.Context) error
+}
+
+// Close delivers what the notifier is still holding, in order: summaries of
+// open cooldown windows, pending digests, then exec hooks in flight and
+// unacknowledged broker publishes. Digests quiet hours are holding back are
+// kept for HeldDigests instead. It gives up when ctx ends. Nothing should
+// be sent after Close.
+func (n *Notifier) Close(ctx context.Context) error {
+	if n.cooldowns != nil {
+		n.cooldowns.flush()
+	}
+
+	errs := make(chan error, len(n.router.all))
+	for _, s := range n.router.all {
+		go func(s Sink) {
+			var err error
+			if d, ok := s.(*digestSink); ok {
+				d.stop()
+				var held *types.HeldDigest
+				if held, err = d.drain(); held != nil {
+					n.mu.Lock()
+					n.held = append(n.held, *held)
+					n.mu.Unlock()
+				}
+			}
+			if c, ok := unwrap(s).(closer); ok {
+				err = errors.Join(err, c.close(ctx))
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s.Name(), err)
+			}
+			errs <- err
+		}(s)
+	}
+
+	var all []error
+	for range n.router.all {
+		select {
+		case err := <-errs:
+			all = append(all, err)
+		case <-ctx.Done():
+			return errors.Join(append(all, ctx.Err())...)
 		}
-		fields = append(fields, map[string]interface{}{"name": "Wallet", "value": wallet, "inline": true})
 	}
-	fields = append(fields, map[string]interface{}{"name": "Reason", "value": d.Reason, "inline": false})
-	return fields
+	return errors.Join(all...)
+}
+
+// HeldDigests returns the digest batches Close could not deliver because the
+// sink was in quiet hours, to be saved and handed to RestoreDigests by the
+// next run.
+func (n *Notifier) HeldDigests() []types.HeldDigest {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return append([]types.HeldDigest(nil), n.held...)
+}
+
+// RestoreDigests puts batches held by a previous run back into their digest
+// sinks. Batches for sinks that no longer exist or no longer digest are
+// dropped with a warning.
+func (n *Notifier) RestoreDigests(held []types.HeldDigest) {
+	for _, h := range held {
+		if d, ok := n.router.byName[h.Sink].(*digestSink); ok {
+			d.restore(h)
+			continue
+		}
+		notifierLog(h.Sink).Warn("dropping held digest, sink no longer digests", "detections", len(h.Detections))
+	}
+}
+
+// sendSummary reports alerts suppressed by a cooldown window to the sinks the
+// latest of them would have been routed to.
+func (n *Notifier) sendSummary(w *window) {
+	if w.count == 0 {
+		return
+	}
+
+	m := w.message()
+	for _, s := range n.router.route(w.last) {
+		err := deliver(context.Background(), s, m.Kind, func() error { return s.SendMessage(m) })
+		if err != nil {
+			notifierLog(s.Name()).Error("summary delivery failed", "err", err)
+		}
+	}
+}
+
+// consoleSink writes each detection to stdout using the configured template.
+type consoleSink struct {
+	name string
+	tmpl *template.Template
+}
+
+func newConsoleSink(name, preset, path string) (*consoleSink, error) {
+	if preset == "" {
+		preset = "verbose"
+	}
+	tmpl, err := loadTemplate(name, preset, path)
+	if err != nil {
+		return nil, err
+	}
+	return &consoleSink{name: name, tmpl: tmpl}, nil
+}
+
+func (s *consoleSink) Name() string { return s.name }
+
+func (s *consoleSink) Send(d types.DetectedTrade) error {
+	out, err := render(s.tmpl, d)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
+func (s *consoleSink) SendMessage(m Message) error {
+	_, err := fmt.Printf("\n[%s] %s\n%s\n\n", m.Kind, m.Title, m.Text)
+	return err
 }
 
 func getOutcome(market *types.Market, assetID string) string {
@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// PayloadVersion is bumped whenever a field in Payload is renamed, removed or
+// changes meaning. Adding fields does not bump it.
+const PayloadVersion = 1
+
+// Payload is the stable, versioned JSON representation of a detection shared
+// by every machine-facing sink.
+type Payload struct {
+	Version     int       `json:"version"`
+	Type        string    `json:"type"`
+	MarketID    string    `json:"marketId"`
+	ConditionID string    `json:"conditionId"`
+	MarketSlug  string    `json:"marketSlug"`
+	EventSlug   string    `json:"eventSlug"`
+	Question    string    `json:"question"`
+	AssetID     string    `json:"assetId"`
+	Outcome     string    `json:"outcome"`
+	Side        string    `json:"side"`
+	Price       float64   `json:"price"`
+	Size        float64   `json:"size"`
+	UsdValue    float64   `json:"usdValue"`
+	Reason      string    `json:"reason"`
+	Reasons     []string  `json:"reasons"`
+	ReasonTypes []string  `json:"reasonTypes"`
+	Severity    string    `json:"severity"`
+	Wallet      string    `json:"wallet,omitempty"`
+	Trader      string    `json:"trader,omitempty"`
+	WhaleName   string    `json:"whaleName,omitempty"`
+	Muted       bool      `json:"muted,omitempty"`
+	URL         string    `json:"url"`
+	TradeTime   time.Time `json:"tradeTime"`
+	DetectedAt  time.Time `json:"detectedAt"`
+}
+
+func NewPayload(d types.DetectedTrade) Payload {
+	reasons := d.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	reasonTypes := d.ReasonTypes
+	if reasonTypes == nil {
+		reasonTypes = []string{}
+	}
+	severity := d.Severity
+	if severity == "" {
+		severity = types.SeverityInfo
+	}
+
+	detectedAt := d.DetectedAt
+	if detectedAt.IsZero() {
+		detectedAt = time.Now()
+	}
+
+	return Payload{
+		Version:     PayloadVersion,
+		Type:        "detection",
+		MarketID:    d.Market.ID,
+		ConditionID: d.Market.ConditionID,
+		MarketSlug:  d.Market.Slug,
+		EventSlug:   d.Market.EventSlug(),
+		Question:    d.Market.Question,
+		AssetID:     d.AssetID,
+		Outcome:     getOutcome(d.Market, d.AssetID),
+		Side:        d.Side,
+		Price:       d.Price,
+		Size:        d.Size,
+		UsdValue:    d.UsdValue,
+		Reason:      d.Reason,
+		Reasons:     reasons,
+		ReasonTypes: reasonTypes,
+		Severity:    severity,
+		Wallet:      d.Wallet,
+		Trader:      d.Trader,
+		WhaleName:   d.WhaleName,
+		Muted:       d.Muted,
+		URL:         fmt.Sprintf("https://polymarket.com/event/%s", d.Market.Slug),
+		TradeTime:   parseTimestamp(d.Timestamp).UTC(),
+		DetectedAt:  detectedAt.UTC(),
+	}
+}
+
+// TradePayload is the stable JSON representation of a raw trade on the
+// trade tape, versioned alongside Payload.
+type TradePayload struct {
+	Version     int       `json:"version"`
+	Type        string    `json:"type"`
+	ConditionID string    `json:"conditionId"`
+	MarketSlug  string    `json:"marketSlug"`
+	EventSlug   string    `json:"eventSlug"`
+	Question    string    `json:"question"`
+	AssetID     string    `json:"assetId"`
+	Outcome     string    `json:"outcome"`
+	Side        string    `json:"side"`
+	Price       float64   `json:"price"`
+	Size        float64   `json:"size"`
+	UsdValue    float64   `json:"usdValue"`
+	TradeTime   time.Time `json:"tradeTime"`
+	ReceivedAt  time.Time `json:"receivedAt"`
+}
+
+func NewTradePayload(t types.MarketTrade) TradePayload {
+	return TradePayload{
+		Version:     PayloadVersion,
+		Type:        "trade",
+		ConditionID: t.Market.ConditionID,
+		MarketSlug:  t.Market.Slug,
+		EventSlug:   t.Market.EventSlug(),
+		Question:    t.Market.Question,
+		AssetID:     t.AssetID,
+		Outcome:     getOutcome(t.Market, t.AssetID),
+		Side:        t.Side,
+		Price:       t.Price,
+		Size:        t.Size,
+		UsdValue:    t.UsdValue,
+		TradeTime:   parseTimestamp(t.Timestamp).UTC(),
+		ReceivedAt:  t.ReceivedAt.UTC(),
+	}
+}
@@ -0,0 +1,75 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// quietSink wraps a sink so that during its quiet hours only critical
+// detections and messages are delivered.
+type quietSink struct {
+	Sink
+	start int
+	end   int
+	loc   *time.Location
+}
+
+// newQuietSink parses hours as "HH:MM-HH:MM" in the named timezone (local
+// time when empty). Ranges may wrap past midnight, e.g. "22:00-07:00".
+func newQuietSink(sink Sink, hours, timezone string) (*quietSink, error) {
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", hours)
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours %q: %w", hours, err)
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours %q: %w", hours, err)
+	}
+
+	loc := time.Local
+	if timezone != "" {
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	return &quietSink{Sink: sink, start: start, end: end, loc: loc}, nil
+}
+
+func (q *quietSink) Send(d types.DetectedTrade) error {
+	if d.Severity != types.SeverityCritical && q.quiet(time.Now()) {
+		return nil
+	}
+	return q.Sink.Send(d)
+}
+
+func (q *quietSink) SendMessage(m Message) error {
+	if m.Severity != types.SeverityCritical && q.quiet(time.Now()) {
+		return nil
+	}
+	return q.Sink.SendMessage(m)
+}
+
+func (q *quietSink) quiet(now time.Time) bool {
+	now = now.In(q.loc)
+	minute := now.Hour()*60 + now.Minute()
+	if q.start <= q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+func TestQuietHours(t *testing.T) {
+	tests := []struct {
+		hours string
+		clock string
+		want  bool
+	}{
+		// Crossing midnight.
+		{"22:00-07:00", "21:59", false},
+		{"22:00-07:00", "22:00", true},
+		{"22:00-07:00", "23:59", true},
+		{"22:00-07:00", "00:00", true},
+		{"22:00-07:00", "06:59", true},
+		{"22:00-07:00", "07:00", false},
+		{"22:00-07:00", "12:00", false},
+		// Ending at midnight.
+		{"20:00-00:00", "23:59", true},
+		{"20:00-00:00", "00:00", false},
+		// Starting at midnight.
+		{"00:00-06:00", "00:00", true},
+		{"00:00-06:00", "05:59", true},
+		{"00:00-06:00", "23:59", false},
+		// Within one day.
+		{"09:00-17:30", "08:59", false},
+		{"09:00-17:30", "09:00", true},
+		{"09:00-17:30", "17:29", true},
+		{"09:00-17:30", "17:30", false},
+		// An empty range is never quiet.
+		{"08:00-08:00", "08:00", false},
+		{"08:00-08:00", "20:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hours+"@"+tt.clock, func(t *testing.T) {
+			q, err := newQuietSink(&recordingSink{name: "slack"}, tt.hours, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+			clock, _ := time.Parse("15:04", tt.clock)
+			now := time.Date(2026, 3, 14, clock.Hour(), clock.Minute(), 30, 0, time.UTC)
+			if got := q.quiet(now); got != tt.want {
+				t.Errorf("quiet = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursTimezone(t *testing.T) {
+	q, err := newQuietSink(&recordingSink{name: "slack"}, "22:00-07:00", "Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no tz database: %v", err)
+	}
+	// 14:00 UTC is 23:00 in Tokyo; 23:00 UTC is 08:00 the next day there.
+	if !q.quiet(time.Date(2026, 3, 14, 14, 0, 0, 0, time.UTC)) {
+		t.Error("23:00 Tokyo time should be quiet")
+	}
+	if q.quiet(time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)) {
+		t.Error("08:00 Tokyo time should not be quiet")
+	}
+}
+
+func TestNewQuietSinkErrors(t *testing.T) {
+	for _, tt := range []struct{ hours, timezone string }{
+		{"22:00", ""},
+		{"22:00-7", ""},
+		{"25:00-07:00", ""},
+		{"night-day", ""},
+		{"22:00-07:00", "Mars/Olympus_Mons"},
+	} {
+		if _, err := newQuietSink(&recordingSink{name: "slack"}, tt.hours, tt.timezone); err == nil {
+			t.Errorf("newQuietSink(%q, %q): want error", tt.hours, tt.timezone)
+		}
+	}
+}
+
+func TestQuietSinkHoldsAllButCritical(t *testing.T) {
+	// A two-hour window around now, which may itself cross midnight.
+	now := time.Now().UTC()
+	hours := fmt.Sprintf("%s-%s", now.Add(-time.Hour).Format("15:04"), now.Add(time.Hour).Format("15:04"))
+
+	inner := &recordingSink{name: "slack"}
+	q, err := newQuietSink(inner, hours, "UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := testDetection()
+	q.Send(d)
+	q.SendMessage(Message{Kind: "summary", Title: "2 more", Severity: types.SeverityWarning})
+	if got, _ := inner.counts(); got != 0 {
+		t.Errorf("%d detections delivered during quiet hours, want 0", got)
+	}
+
+	d.Severity = types.SeverityCritical
+	q.Send(d)
+	q.SendMessage(Message{Kind: KindHealth, Title: "stale", Severity: types.SeverityCritical})
+	if detections, messages := inner.counts(); detections != 1 || messages != 1 {
+		t.Errorf("delivered %d detections and %d messages, want the critical ones", detections, messages)
+	}
+}
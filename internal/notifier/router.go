@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// router picks the sinks a detection is delivered to. Without routes, or a
+// default, every detection goes to every sink except incident sinks, which
+// page someone and so must be named by a route or the default.
+type router struct {
+	routes   []types.Route
+	fallback []string
+	all      []Sink
+	defaults []Sink
+	byName   map[string]Sink
+}
+
+func newRouter(sinks []Sink, routes []types.Route, fallback []string) (*router, error) {
+	r := &router{
+		routes:   routes,
+		fallback: fallback,
+		all:      sinks,
+		byName:   make(map[string]Sink),
+	}
+
+	for _, s := range sinks {
+		if _, dup := r.byName[s.Name()]; dup {
+			return nil, fmt.Errorf("duplicate sink name %q", s.Name())
+		}
+		r.byName[s.Name()] = s
+		if _, ok := unwrap(s).(incidentSink); !ok {
+			r.defaults = append(r.defaults, s)
+		}
+	}
+
+	check := func(where string, names []string) error {
+		for _, name := range names {
+			if _, ok := r.byName[name]; !ok {
+				return fmt.Errorf("%s: unknown sink %q", where, name)
+			}
+		}
+		return nil
+	}
+	for i, route := range routes {
+		where := route.Name
+		if where == "" {
+			where = fmt.Sprintf("route #%d", i+1)
+		}
+		if err := check(where, route.Sinks); err != nil {
+			return nil, err
+		}
+	}
+	if err := check("default", fallback); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *router) route(d types.DetectedTrade) []Sink {
+	if len(r.routes) == 0 && r.fallback == nil {
+		return r.defaults
+	}
+
+	var names []string
+	matched := false
+	for _, route := range r.routes {
+		if !matchRoute(route.Match, d) {
+			continue
+		}
+		matched = true
+		names = append(names, route.Sinks...)
+		if !route.Continue {
+			break
+		}
+	}
+
+	if !matched {
+		if r.fallback == nil {
+			return r.defaults
+		}
+		names = r.fallback
+	}
+
+	seen := make(map[string]bool)
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		sinks = append(sinks, r.byName[name])
+	}
+	return sinks
+}
+
+func matchRoute(m types.RouteMatch, d types.DetectedTrade) bool {
+	if len(m.EventSlugs) > 0 && !containsFold(m.EventSlugs, d.Market.EventSlug()) && !containsFold(m.EventSlugs, d.Market.Slug) {
+		return false
+	}
+	if len(m.ConditionIDs) > 0 && !containsFold(m.ConditionIDs, d.Market.ConditionID) {
+		return false
+	}
+	if len(m.Whales) > 0 && !matchWhale(m.Whales, d) {
+		return false
+	}
+	if len(m.Reasons) > 0 && !containsAnyFold(m.Reasons, d.ReasonTypes) {
+		return false
+	}
+	if len(m.Sides) > 0 && !containsFold(m.Sides, d.Side) {
+		return false
+	}
+	if m.MinSeverity != "" && types.SeverityRank(d.Severity) < types.SeverityRank(m.MinSeverity) {
+		return false
+	}
+	if m.MinUSD > 0 && d.UsdValue < m.MinUSD {
+		return false
+	}
+	if m.MaxUSD > 0 && d.UsdValue > m.MaxUSD {
+		return false
+	}
+	if m.MinPrice > 0 && d.Price < m.MinPrice {
+		return false
+	}
+	if m.MaxPrice > 0 && d.Price > m.MaxPrice {
+		return false
+	}
+	return true
+}
+
+// matchWhale accepts whale names or addresses; "*" matches any tracked whale.
+func matchWhale(whales []string, d types.DetectedTrade) bool {
+	if d.WhaleName == "" {
+		return false
+	}
+	for _, w := range whales {
+		if w == "*" || strings.EqualFold(w, d.WhaleName) || strings.EqualFold(w, d.Wallet) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, v string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnyFold(list, values []string) bool {
+	for _, v := range values {
+		if containsFold(list, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package notifier
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// namedSink is a Sink that only has a name; routing never sends to it.
+type namedSink string
+
+func (s namedSink) Name() string                   { return string(s) }
+func (s namedSink) Send(types.DetectedTrade) error { return nil }
+func (s namedSink) SendMessage(Message) error      { return nil }
+
+// pagerSink stands in for an incident sink.
+type pagerSink struct{ namedSink }
+
+func (pagerSink) incident() {}
+
+func TestMatchRoute(t *testing.T) {
+	whale := testDetection()
+	whale.WhaleName = "Theo"
+	whale.ReasonTypes = []string{types.ReasonLarge, types.ReasonWhale}
+
+	tests := []struct {
+		name  string
+		match types.RouteMatch
+		d     types.DetectedTrade
+		want  bool
+	}{
+		{"empty match", types.RouteMatch{}, testDetection(), true},
+		{"event slug", types.RouteMatch{EventSlugs: []string{"FED.rates 2026"}}, testDetection(), true},
+		{"market slug", types.RouteMatch{EventSlugs: []string{"fed-cut-march"}}, testDetection(), true},
+		{"other market", types.RouteMatch{EventSlugs: []string{"us-election"}}, testDetection(), false},
+		{"condition id", types.RouteMatch{ConditionIDs: []string{"0xABC123"}}, testDetection(), true},
+		{"other condition id", types.RouteMatch{ConditionIDs: []string{"0xdef"}}, testDetection(), false},
+		{"reason", types.RouteMatch{Reasons: []string{"whale", "large"}}, testDetection(), true},
+		{"other reason", types.RouteMatch{Reasons: []string{"early"}}, testDetection(), false},
+		{"side", types.RouteMatch{Sides: []string{"BUY"}}, testDetection(), true},
+		{"other side", types.RouteMatch{Sides: []string{"sell"}}, testDetection(), false},
+		{"severity at minimum", types.RouteMatch{MinSeverity: types.SeverityWarning}, testDetection(), true},
+		{"severity above minimum", types.RouteMatch{MinSeverity: types.SeverityInfo}, testDetection(), true},
+		{"severity below minimum", types.RouteMatch{MinSeverity: types.SeverityCritical}, testDetection(), false},
+		{"min usd met", types.RouteMatch{MinUSD: 5000}, testDetection(), true},
+		{"min usd missed", types.RouteMatch{MinUSD: 5000.01}, testDetection(), false},
+		{"max usd met", types.RouteMatch{MaxUSD: 5000}, testDetection(), true},
+		{"max usd missed", types.RouteMatch{MaxUSD: 4999}, testDetection(), false},
+		{"price in range", types.RouteMatch{MinPrice: 0.4, MaxPrice: 0.5}, testDetection(), true},
+		{"price out of range", types.RouteMatch{MinPrice: 0.5}, testDetection(), false},
+		{"whale by name", types.RouteMatch{Whales: []string{"theo"}}, whale, true},
+		{"whale by address", types.RouteMatch{Whales: []string{"0x1234567890ABCDEF1234567890abcdef12345678"}}, whale, true},
+		{"any whale", types.RouteMatch{Whales: []string{"*"}}, whale, true},
+		{"any whale, not a whale", types.RouteMatch{Whales: []string{"*"}}, testDetection(), false},
+		{"all fields must match", types.RouteMatch{Reasons: []string{"large"}, MinUSD: 10000}, testDetection(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRoute(tt.match, tt.d); got != tt.want {
+				t.Errorf("matchRoute = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	sinks := []Sink{namedSink("console"), namedSink("slack"), namedSink("ops"), pagerSink{"pager"}}
+
+	critical := testDetection()
+	critical.Severity = types.SeverityCritical
+	critical.UsdValue = 60000
+
+	fed := []types.Route{{Name: "fed", Match: types.RouteMatch{EventSlugs: []string{"fed.rates 2026"}}, Sinks: []string{"slack"}}}
+	big := types.Route{Name: "big", Match: types.RouteMatch{MinUSD: 50000}, Sinks: []string{"pager", "slack"}}
+
+	tests := []struct {
+		name     string
+		routes   []types.Route
+		fallback []string
+		d        types.DetectedTrade
+		want     []string
+	}{
+		{
+			name: "no routes skips incident sinks",
+			d:    critical,
+			want: []string{"console", "slack", "ops"},
+		},
+		{
+			name:   "unmatched without default skips incident sinks",
+			routes: []types.Route{big},
+			d:      testDetection(),
+			want:   []string{"console", "slack", "ops"},
+		},
+		{
+			name:     "unmatched falls back to default",
+			routes:   []types.Route{big},
+			fallback: []string{"ops"},
+			d:        testDetection(),
+			want:     []string{"ops"},
+		},
+		{
+			name:     "empty default drops",
+			routes:   []types.Route{big},
+			fallback: []string{},
+			d:        testDetection(),
+			want:     []string{},
+		},
+		{
+			name:     "default applies without routes",
+			fallback: []string{"pager"},
+			d:        testDetection(),
+			want:     []string{"pager"},
+		},
+		{
+			name:     "route names an incident sink",
+			routes:   []types.Route{big},
+			fallback: []string{"ops"},
+			d:        critical,
+			want:     []string{"pager", "slack"},
+		},
+		{
+			name:   "first match wins",
+			routes: append(append([]types.Route{}, fed...), big),
+			d:      critical,
+			want:   []string{"slack"},
+		},
+		{
+			name:   "continue collects later matches without duplicates",
+			routes: []types.Route{{Match: fed[0].Match, Sinks: []string{"slack"}, Continue: true}, big},
+			d:      critical,
+			want:   []string{"slack", "pager"},
+		},
+		{
+			name:   "continue into no further match",
+			routes: []types.Route{{Match: fed[0].Match, Sinks: []string{"slack"}, Continue: true}, big},
+			d:      testDetection(),
+			want:   []string{"slack"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newRouter(sinks, tt.routes, tt.fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, s := range r.route(tt.d) {
+				got = append(got, s.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("route = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRouterRejectsUnknownSinks(t *testing.T) {
+	sinks := []Sink{namedSink("console")}
+	if _, err := newRouter(sinks, []types.Route{{Name: "fed", Sinks: []string{"slack"}}}, nil); err == nil {
+		t.Error("route naming an unknown sink: want error")
+	}
+	if _, err := newRouter(sinks, nil, []string{"slack"}); err == nil {
+		t.Error("default naming an unknown sink: want error")
+	}
+	if _, err := newRouter([]Sink{namedSink("console"), namedSink("console")}, nil, nil); err == nil {
+		t.Error("duplicate sink names: want error")
+	}
+}
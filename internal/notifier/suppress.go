@@ -0,0 +1,175 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// cooldowns suppresses repeat alerts for the same market side or wallet. The
+// first detection for a key opens a window; detections inside it are counted
+// instead of delivered, and a single summary is emitted when it closes.
+// Critical detections always pass.
+type cooldowns struct {
+	market  time.Duration
+	wallet  time.Duration
+	summary func(w *window)
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	label    string
+	noun     string
+	length   time.Duration
+	until    time.Time
+	count    int
+	usd      float64
+	severity string
+	last     types.DetectedTrade
+	timer    *time.Timer
+}
+
+func newCooldowns(market, wallet time.Duration, summary func(w *window)) *cooldowns {
+	if market <= 0 && wallet <= 0 {
+		return nil
+	}
+	return &cooldowns{
+		market:  market,
+		wallet:  wallet,
+		summary: summary,
+		windows: make(map[string]*window),
+	}
+}
+
+func (c *cooldowns) allow(d types.DetectedTrade) bool {
+	if d.Severity == types.SeverityCritical {
+		return true
+	}
+
+	type candidate struct {
+		key, label, noun string
+		length           time.Duration
+	}
+	var keys []candidate
+	if c.market > 0 {
+		noun := "large " + strings.ToLower(d.Side) + "s"
+		if d.Side == "" {
+			noun = "alerts"
+		}
+		keys = append(keys, candidate{
+			key:    "market:" + d.Market.ConditionID + ":" + strings.ToLower(d.Side),
+			label:  "on " + d.Market.Question,
+			noun:   noun,
+			length: c.market,
+		})
+	}
+	if c.wallet > 0 && d.Wallet != "" {
+		who := d.Trader
+		if who == "" {
+			who = d.Wallet
+		}
+		keys = append(keys, candidate{
+			key:    "wallet:" + strings.ToLower(d.Wallet),
+			label:  "by " + who,
+			noun:   "trades",
+			length: c.wallet,
+		})
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, k := range keys {
+		w := c.windows[k.key]
+		if w == nil || !now.Before(w.until) {
+			continue
+		}
+		w.count++
+		w.usd += d.UsdValue
+		w.last = d
+		if types.SeverityRank(d.Severity) > types.SeverityRank(w.severity) {
+			w.severity = d.Severity
+		}
+		if w.timer == nil {
+			key := k.key
+			w.timer = time.AfterFunc(w.until.Sub(now), func() { c.expire(key, w) })
+		}
+		return false
+	}
+
+	c.sweep(now)
+	for _, k := range keys {
+		c.windows[k.key] = &window{
+			label:  k.label,
+			noun:   k.noun,
+			length: k.length,
+			until:  now.Add(k.length),
+		}
+	}
+	return true
+}
+
+func (c *cooldowns) expire(key string, w *window) {
+	c.mu.Lock()
+	if c.windows[key] == w {
+		delete(c.windows, key)
+	}
+	c.mu.Unlock()
+
+	c.summary(w)
+}
+
+// flush closes every window early, sending the summaries of those that
+// suppressed anything.
+func (c *cooldowns) flush() {
+	c.mu.Lock()
+	windows := c.windows
+	c.windows = make(map[string]*window)
+	c.mu.Unlock()
+
+	for _, w := range windows {
+		if w.timer != nil && w.timer.Stop() {
+			c.summary(w)
+		}
+	}
+}
+
+// sweep drops closed windows that never suppressed anything and so have no
+// timer to remove them. Callers must hold c.mu.
+func (c *cooldowns) sweep(now time.Time) {
+	if len(c.windows) < 1024 {
+		return
+	}
+	for key, w := range c.windows {
+		if w.timer == nil && !now.Before(w.until) {
+			delete(c.windows, key)
+		}
+	}
+}
+
+func (w *window) message() Message {
+	return Message{
+		Kind:     "summary",
+		Title:    fmt.Sprintf("%d more %s %s in the last %s", w.count, w.noun, w.label, formatDuration(w.length)),
+		Text:     fmt.Sprintf("%s total. Latest: %s\nhttps://polymarket.com/event/%s", formatUSD(w.usd), w.last.Reason, w.last.Market.Slug),
+		Severity: w.severity,
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,198 @@
+package notifier
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// summaries collects the windows cooldowns report as they close.
+type summaries struct {
+	mu      sync.Mutex
+	windows []*window
+	closed  chan struct{}
+}
+
+func newSummaries() *summaries {
+	return &summaries{closed: make(chan struct{}, 16)}
+}
+
+func (s *summaries) add(w *window) {
+	s.mu.Lock()
+	s.windows = append(s.windows, w)
+	s.mu.Unlock()
+	s.closed <- struct{}{}
+}
+
+func (s *summaries) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no summary")
+	}
+}
+
+func (s *summaries) list() []*window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*window(nil), s.windows...)
+}
+
+func TestNewCooldownsDisabled(t *testing.T) {
+	if c := newCooldowns(0, -time.Minute, func(*window) {}); c != nil {
+		t.Error("cooldowns without a positive duration should be nil")
+	}
+}
+
+func TestMarketCooldown(t *testing.T) {
+	s := newSummaries()
+	c := newCooldowns(200*time.Millisecond, 0, s.add)
+
+	d := testDetection()
+	if !c.allow(d) {
+		t.Fatal("first detection suppressed")
+	}
+	second := d
+	second.UsdValue = 7000
+	if c.allow(second) {
+		t.Error("repeat on the same market side allowed")
+	}
+	third := d
+	third.Severity = types.SeverityInfo
+	third.Reason = "Large trade: $2.0K"
+	third.UsdValue = 2000
+	if c.allow(third) {
+		t.Error("second repeat allowed")
+	}
+
+	other := d
+	other.Side = "sell"
+	if !c.allow(other) {
+		t.Error("other side of the market suppressed")
+	}
+	critical := d
+	critical.Severity = types.SeverityCritical
+	if !c.allow(critical) {
+		t.Error("critical detection suppressed")
+	}
+
+	s.wait(t)
+	got := s.list()
+	if len(got) != 1 {
+		t.Fatalf("%d summaries, want 1", len(got))
+	}
+	w := got[0]
+	if w.count != 2 || w.usd != 9000 || w.severity != types.SeverityWarning || w.last.Reason != third.Reason {
+		t.Errorf("window = count %d, usd %v, severity %q, last %q", w.count, w.usd, w.severity, w.last.Reason)
+	}
+	m := w.message()
+	if want := "2 more large buys on Will the Fed cut rates in March? in the last 200ms"; m.Title != want {
+		t.Errorf("title = %q, want %q", m.Title, want)
+	}
+	if m.Kind != "summary" || m.Severity != types.SeverityWarning {
+		t.Errorf("message kind %q, severity %q", m.Kind, m.Severity)
+	}
+
+	// The expired window no longer suppresses.
+	if !c.allow(d) {
+		t.Error("detection after the window closed suppressed")
+	}
+}
+
+func TestWalletCooldown(t *testing.T) {
+	s := newSummaries()
+	c := newCooldowns(0, time.Hour, s.add)
+
+	d := testDetection()
+	if !c.allow(d) {
+		t.Fatal("first detection suppressed")
+	}
+	elsewhere := d
+	elsewhere.Market = &types.Market{ConditionID: "0xdef", Question: "Other", Slug: "other"}
+	if c.allow(elsewhere) {
+		t.Error("same wallet on another market allowed")
+	}
+	anon := d
+	anon.Wallet = ""
+	if !c.allow(anon) {
+		t.Error("detection without a wallet suppressed")
+	}
+
+	c.flush()
+	s.wait(t)
+	if got := s.list(); len(got) != 1 || got[0].label != "by "+d.Wallet || got[0].noun != "trades" {
+		t.Errorf("summaries = %+v", got)
+	}
+}
+
+func TestCooldownFlush(t *testing.T) {
+	s := newSummaries()
+	c := newCooldowns(time.Hour, 0, s.add)
+
+	quiet := testDetection()
+	busy := testDetection()
+	busy.Market = &types.Market{ConditionID: "0xdef", Question: "Other", Slug: "other"}
+	c.allow(quiet)
+	c.allow(busy)
+	c.allow(busy)
+
+	// Only the window that suppressed something has a summary to send.
+	c.flush()
+	s.wait(t)
+	if got := s.list(); len(got) != 1 || got[0].label != "on Other" {
+		t.Errorf("summaries = %+v", got)
+	}
+	if len(c.windows) != 0 {
+		t.Errorf("%d windows left after flush", len(c.windows))
+	}
+	if !c.allow(busy) {
+		t.Error("detection after flush suppressed")
+	}
+}
+
+func TestCooldownSweep(t *testing.T) {
+	c := newCooldowns(time.Minute, 0, func(*window) {})
+	now := time.Now()
+
+	timer := time.AfterFunc(time.Hour, func() {})
+	defer timer.Stop()
+	for i := 0; i < 1100; i++ {
+		c.windows[fmt.Sprintf("closed:%d", i)] = &window{until: now.Add(-time.Second)}
+	}
+	c.windows["open"] = &window{until: now.Add(time.Minute)}
+	c.windows["suppressing"] = &window{until: now.Add(-time.Second), timer: timer}
+
+	c.sweep(now)
+	if len(c.windows) != 2 || c.windows["open"] == nil || c.windows["suppressing"] == nil {
+		t.Errorf("after sweep %d windows, want only the open and the suppressing one", len(c.windows))
+	}
+
+	// Small maps are left for allow to overwrite.
+	c.windows["closed"] = &window{until: now.Add(-time.Second)}
+	c.sweep(now)
+	if c.windows["closed"] == nil {
+		t.Error("sweep below the threshold removed a window")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{2 * time.Hour, "2h"},
+		{90 * time.Minute, "90m"},
+		{10 * time.Minute, "10m"},
+		{90 * time.Second, "1m30s"},
+		{50 * time.Millisecond, "50ms"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	signatureHeader = "X-Polymarket-Tool-Signature"
+	timestampHeader = "X-Polymarket-Tool-Timestamp"
+)
+
+// jsonWebhookSink posts a Payload (or a templated body rendered from it) to a
+// generic HTTP endpoint. When a secret is configured every request carries an
+// HMAC-SHA256 signature over "<timestamp>.<body>" so receivers can verify the
+// sender and reject replays outside their tolerance window.
+type jsonWebhookSink struct {
+	name   string
+	url    string
+	secret string
+	tmpl   *template.Template
+	http   *http.Client
+}
+
+func newJSONWebhookSink(name, url, secret, templatePath string, client *http.Client) (*jsonWebhookSink, error) {
+	s := &jsonWebhookSink{name: name, url: url, secret: secret, http: client}
+
+	tmpl, err := loadTemplate(name, "", templatePath)
+	if err != nil {
+		return nil, err
+	}
+	s.tmpl = tmpl
+
+	return s, nil
+}
+
+func (s *jsonWebhookSink) Name() string { return s.name }
+
+func (s *jsonWebhookSink) Send(d types.DetectedTrade) error {
+	var body []byte
+	var err error
+	if s.tmpl != nil {
+		body, err = render(s.tmpl, d)
+	} else {
+		body, err = json.Marshal(NewPayload(d))
+	}
+	if err != nil {
+		return err
+	}
+	return s.post(body)
+}
+
+func (s *jsonWebhookSink) SendMessage(m Message) error {
+	body, err := json.Marshal(NewMessagePayload(m))
+	if err != nil {
+		return err
+	}
+	return s.post(body)
+}
+
+func (s *jsonWebhookSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(timestampHeader, ts)
+		req.Header.Set(signatureHeader, "sha256="+sign(s.secret, ts, body))
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sign(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// goldenPayload is the JSON of NewPayload(testDetection()). Receivers parse
+// this, so a change here must come with a PayloadVersion bump unless it only
+// adds fields.
+const goldenPayload = `{"version":1,"type":"detection","marketId":"512","conditionId":"0xabc123","marketSlug":"fed-cut-march","eventSlug":"fed.rates 2026","question":"Will the Fed cut rates in March?","assetId":"111","outcome":"Yes","side":"buy","price":0.42,"size":11904.76,"usdValue":5000,"reason":"Large trade: $5.0K","reasons":["Large trade: $5.0K"],"reasonTypes":["large"],"severity":"warning","wallet":"0x1234567890abcdef1234567890abcdef12345678","url":"https://polymarket.com/event/fed-cut-march","tradeTime":"2026-01-01T00:00:00Z","detectedAt":"2026-01-01T00:00:02Z"}`
+
+func TestSign(t *testing.T) {
+	// Computed independently: HMAC-SHA256("whsec_test", "1767225602." + body).
+	const want = "b0ba82f7b081033165eee473fa019a4c4e8e503ae865c56ad862eaae644a0d55"
+	if got := sign("whsec_test", "1767225602", []byte(`{"hello":"world"}`)); got != want {
+		t.Errorf("sign = %s, want %s", got, want)
+	}
+	if got := sign("other", "1767225602", []byte(`{"hello":"world"}`)); got == want {
+		t.Error("sign ignored the secret")
+	}
+	if got := sign("whsec_test", "1767225603", []byte(`{"hello":"world"}`)); got == want {
+		t.Error("sign ignored the timestamp")
+	}
+}
+
+func TestNewPayload(t *testing.T) {
+	got, err := json.Marshal(NewPayload(testDetection()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != goldenPayload {
+		t.Errorf("payload:\n got %s\nwant %s", got, goldenPayload)
+	}
+}
+
+func TestNewPayloadDefaults(t *testing.T) {
+	d := testDetection()
+	d.Reasons, d.ReasonTypes, d.Severity = nil, nil, ""
+	d.AssetID = "999"
+
+	p := NewPayload(d)
+	if p.Reasons == nil || p.ReasonTypes == nil {
+		t.Error("nil reasons should encode as empty arrays, not null")
+	}
+	if p.Severity != types.SeverityInfo {
+		t.Errorf("severity = %q, want %q", p.Severity, types.SeverityInfo)
+	}
+	if p.Outcome != "Unknown" {
+		t.Errorf("outcome = %q, want Unknown", p.Outcome)
+	}
+}
+
+func TestJSONWebhookSinkSignsBody(t *testing.T) {
+	var (
+		body    []byte
+		headers http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	defer srv.Close()
+
+	s, err := newJSONWebhookSink("json", srv.URL, "whsec_test", "", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(testDetection()); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != goldenPayload {
+		t.Errorf("body:\n got %s\nwant %s", body, goldenPayload)
+	}
+	if ct := headers.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	ts := headers.Get(timestampHeader)
+	if ts == "" {
+		t.Fatalf("no %s header", timestampHeader)
+	}
+	sig, ok := strings.CutPrefix(headers.Get(signatureHeader), "sha256=")
+	if !ok || sig != sign("whsec_test", ts, body) {
+		t.Errorf("%s = %q does not sign the body", signatureHeader, headers.Get(signatureHeader))
+	}
+}
+
+func TestJSONWebhookSinkUnsignedWithoutSecret(t *testing.T) {
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+	}))
+	defer srv.Close()
+
+	s, err := newJSONWebhookSink("json", srv.URL, "", "", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(testDetection()); err != nil {
+		t.Fatal(err)
+	}
+	if headers.Get(signatureHeader) != "" || headers.Get(timestampHeader) != "" {
+		t.Errorf("unsigned sink sent %s/%s headers", signatureHeader, timestampHeader)
+	}
+}
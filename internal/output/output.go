@@ -0,0 +1,161 @@
+// Package output encodes command results for scripts: JSON, NDJSON or CSV
+// built from the same structs the data files and APIs use, so field names
+// match the JSON tags everywhere. The human-readable table stays with each
+// command.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Table  = "table"
+	JSON   = "json"
+	NDJSON = "ndjson"
+	CSV    = "csv"
+)
+
+// Formats lists the accepted --format values.
+var Formats = []string{Table, JSON, NDJSON, CSV}
+
+// Validate reports whether format is one of Formats.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid format %q (%s)", format, strings.Join(Formats, ", "))
+}
+
+// Write encodes rows, a slice of structs, as format: a JSON array, one JSON
+// object per line, or CSV with a header row of the JSON field names. An empty
+// slice is still a valid document: [] or just the header.
+func Write(w io.Writer, format string, rows interface{}) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("output: want a slice, got %T", rows)
+	}
+
+	switch format {
+	case JSON:
+		if v.IsNil() {
+			rows = []struct{}{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		for i := 0; i < v.Len(); i++ {
+			if err := enc.Encode(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		return writeCSV(w, v)
+	}
+	return fmt.Errorf("output: format %q is not machine-readable", format)
+}
+
+func writeCSV(w io.Writer, rows reflect.Value) error {
+	cw := csv.NewWriter(w)
+	cols := columns(rows.Type().Elem())
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(cols))
+	for i := 0; i < rows.Len(); i++ {
+		row := reflect.Indirect(rows.Index(i))
+		for j, c := range cols {
+			record[j] = cell(row.FieldByIndex(c.index))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type column struct {
+	name  string
+	index []int
+}
+
+// columns lists the exported fields of struct type t in declaration order,
+// named by their JSON tags, with embedded structs flattened as encoding/json
+// does.
+func columns(t reflect.Type) []column {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var cols []column
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && name == "" {
+			// Its fields are visited on their own.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, column{name: name, index: f.Index})
+	}
+	return cols
+}
+
+// cell formats one value: times as RFC 3339, numbers without exponents, and
+// anything structured as JSON.
+func cell(v reflect.Value) string {
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
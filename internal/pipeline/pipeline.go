@@ -0,0 +1,257 @@
+// Package pipeline decouples the WebSocket reader from detection and
+// notification. The reader only queues trades; a pool of workers runs
+// enrichment and detection, and a separate goroutine delivers detections, so
+// a slow order book lookup or webhook never stalls the socket.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// Overflow policies for a full queue.
+const (
+	// OverflowDropOldest discards the oldest queued item to make room, so
+	// the reader never waits and fresh trades win over stale ones.
+	OverflowDropOldest = "drop-oldest"
+	// OverflowBlock makes the producer wait for room, losing nothing but
+	// letting a backlog reach the WebSocket reader.
+	OverflowBlock = "block"
+)
+
+const (
+	stageDetect = "detect"
+	stageNotify = "notify"
+)
+
+// ProcessFunc runs detection for one trade.
+type ProcessFunc func(ctx context.Context, msg types.WsMessage)
+
+// NotifyFunc records and delivers one detection.
+type NotifyFunc func(ctx context.Context, detection types.DetectedTrade)
+
+type Options struct {
+	// Workers is the number of detection workers.
+	Workers int
+	// QueueSize bounds the trades waiting for detection, split evenly
+	// across workers, and separately the detections waiting for delivery.
+	QueueSize int
+	// Overflow is OverflowDropOldest or OverflowBlock.
+	Overflow string
+}
+
+// Pipeline is safe for concurrent use. Trades for the same asset are always
+// handled by the same worker, so they are processed in the order received.
+type Pipeline struct {
+	process ProcessFunc
+	notify  NotifyFunc
+	shards  []*queue
+	pending *queue
+	workers sync.WaitGroup
+	sender  sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	// abandoned makes the workers and sender discard what is left queued
+	// once a Shutdown deadline has passed.
+	abandoned atomic.Bool
+}
+
+type item struct {
+	ctx       context.Context
+	queuedAt  time.Time
+	msg       types.WsMessage
+	detection types.DetectedTrade
+}
+
+// New starts the workers and the notification goroutine.
+func New(opts Options, process ProcessFunc, notify NotifyFunc) (*Pipeline, error) {
+	if opts.Workers < 1 {
+		return nil, fmt.Errorf("pipeline workers must be at least 1, got %d", opts.Workers)
+	}
+	if opts.QueueSize < 1 {
+		return nil, fmt.Errorf("pipeline queue size must be at least 1, got %d", opts.QueueSize)
+	}
+	if opts.Overflow != OverflowDropOldest && opts.Overflow != OverflowBlock {
+		return nil, fmt.Errorf("invalid pipeline overflow policy %q (%s, %s)", opts.Overflow, OverflowDropOldest, OverflowBlock)
+	}
+
+	p := &Pipeline{
+		process: process,
+		notify:  notify,
+		pending: newQueue(stageNotify, opts.QueueSize, opts.Overflow),
+	}
+
+	perShard := (opts.QueueSize + opts.Workers - 1) / opts.Workers
+	metrics.PipelineQueueCapacity.WithLabelValues(stageDetect).Set(float64(perShard * opts.Workers))
+	metrics.PipelineQueueCapacity.WithLabelValues(stageNotify).Set(float64(opts.QueueSize))
+
+	for i := 0; i < opts.Workers; i++ {
+		q := newQueue(stageDetect, perShard, opts.Overflow)
+		p.shards = append(p.shards, q)
+		p.workers.Add(1)
+		go p.work(q)
+	}
+
+	p.sender.Add(1)
+	go p.send()
+
+	return p, nil
+}
+
+// Submit queues a trade for detection. It is the WebSocket trade handler and
+// only waits when the overflow policy is OverflowBlock and the asset's worker
+// is backed up. Trades submitted after Close are discarded.
+func (p *Pipeline) Submit(ctx context.Context, msg types.WsMessage) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+
+	p.shards[shard(msg.AssetID, len(p.shards))].push(item{ctx: ctx, queuedAt: time.Now(), msg: msg})
+}
+
+// Detected queues a detection for delivery. It is the detector's detection
+// handler.
+func (p *Pipeline) Detected(ctx context.Context, detection types.DetectedTrade) {
+	p.pending.push(item{ctx: ctx, queuedAt: time.Now(), detection: detection})
+}
+
+// Close stops accepting trades and returns once everything already queued
+// has been processed and delivered.
+func (p *Pipeline) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	for _, q := range p.shards {
+		close(q.ch)
+	}
+	p.mu.Unlock()
+
+	// Workers may still produce detections until they finish.
+	p.workers.Wait()
+	close(p.pending.ch)
+	p.sender.Wait()
+}
+
+// Shutdown is Close bounded by ctx. If ctx ends first, whatever is still
+// queued is discarded and it returns an error saying how much. Either way,
+// once it returns nothing more is processed or delivered; a trade or
+// detection already being handled is allowed to finish.
+func (p *Pipeline) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		trades := 0
+		for _, q := range p.shards {
+			trades += len(q.ch)
+		}
+		detections := len(p.pending.ch)
+		p.abandoned.Store(true)
+		<-done
+		return fmt.Errorf("%w, discarded %d queued trades and %d detections", ctx.Err(), trades, detections)
+	}
+}
+
+func (p *Pipeline) work(q *queue) {
+	defer p.workers.Done()
+	for it := range q.ch {
+		q.popped(it)
+		if p.abandoned.Load() {
+			continue
+		}
+		p.process(it.ctx, it.msg)
+	}
+}
+
+func (p *Pipeline) send() {
+	defer p.sender.Done()
+	for it := range p.pending.ch {
+		p.pending.popped(it)
+		if p.abandoned.Load() {
+			continue
+		}
+		p.notify(it.ctx, it.detection)
+	}
+}
+
+// shard picks the worker for an asset.
+func shard(assetID string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(assetID))
+	return int(h.Sum32() % uint32(n))
+}
+
+// queue is a bounded channel applying an overflow policy when full.
+type queue struct {
+	stage   string
+	ch      chan item
+	policy  string
+	dropped atomic.Int64
+	depth   prometheus.Gauge
+	wait    prometheus.Observer
+}
+
+func newQueue(stage string, size int, policy string) *queue {
+	return &queue{
+		stage:  stage,
+		ch:     make(chan item, size),
+		policy: policy,
+		depth:  metrics.PipelineQueueDepth.WithLabelValues(stage),
+		wait:   metrics.PipelineWait.WithLabelValues(stage),
+	}
+}
+
+func (q *queue) push(it item) {
+	q.depth.Inc()
+	if q.policy == OverflowBlock {
+		q.ch <- it
+		return
+	}
+
+	for {
+		select {
+		case q.ch <- it:
+			return
+		default:
+		}
+
+		// Full: discard the oldest item. A consumer may have made room
+		// in the meantime, in which case nothing is dropped.
+		select {
+		case <-q.ch:
+			q.depth.Dec()
+			metrics.PipelineDropped.WithLabelValues(q.stage).Inc()
+			if n := q.dropped.Add(1); n%1000 == 1 {
+				logging.For("pipeline").Warn("queue full, dropping oldest", "stage", q.stage, "dropped", n)
+			}
+		default:
+		}
+	}
+}
+
+// popped accounts for an item taken off the queue.
+func (q *queue) popped(it item) {
+	q.depth.Dec()
+	q.wait.Observe(time.Since(it.queuedAt).Seconds())
+}
@@ -0,0 +1,260 @@
+package pipeline
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+// recorder collects the trades processed and detections delivered, in
+// order. Processing a trade detects it, so each trade becomes a detection
+// with its Timestamp as the reason.
+type recorder struct {
+	mu         sync.Mutex
+	processed  []string
+	delivered  []string
+	started    chan string
+	release    chan struct{}
+	blockFirst bool
+}
+
+func newRecorder(blockFirst bool) *recorder {
+	return &recorder{started: make(chan string, 1), release: make(chan struct{}), blockFirst: blockFirst}
+}
+
+func (r *recorder) start(t *testing.T, opts Options) *Pipeline {
+	t.Helper()
+	var p *Pipeline
+	first := true
+	process := func(ctx context.Context, msg types.WsMessage) {
+		r.mu.Lock()
+		block := r.blockFirst && first
+		first = false
+		r.mu.Unlock()
+		if block {
+			r.started <- msg.Timestamp
+			<-r.release
+		}
+
+		r.mu.Lock()
+		r.processed = append(r.processed, msg.AssetID+"/"+msg.Timestamp)
+		r.mu.Unlock()
+		p.Detected(ctx, types.DetectedTrade{AssetID: msg.AssetID, Reason: msg.Timestamp})
+	}
+	notify := func(ctx context.Context, d types.DetectedTrade) {
+		r.mu.Lock()
+		r.delivered = append(r.delivered, d.AssetID+"/"+d.Reason)
+		r.mu.Unlock()
+	}
+
+	var err error
+	p, err = New(opts, process, notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func (r *recorder) results() (processed, delivered []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.processed...), append([]string(nil), r.delivered...)
+}
+
+func trade(asset string, seq int) types.WsMessage {
+	return types.WsMessage{EventType: "last_trade_price", AssetID: asset, Timestamp: strconv.Itoa(seq)}
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	nop := func(context.Context, types.WsMessage) {}
+	deliver := func(context.Context, types.DetectedTrade) {}
+	for _, opts := range []Options{
+		{Workers: 0, QueueSize: 1, Overflow: OverflowBlock},
+		{Workers: 1, QueueSize: 0, Overflow: OverflowBlock},
+		{Workers: 1, QueueSize: 1, Overflow: "drop-newest"},
+	} {
+		if _, err := New(opts, nop, deliver); err == nil {
+			t.Errorf("New(%+v): want error", opts)
+		}
+	}
+}
+
+func TestDropOldest(t *testing.T) {
+	r := newRecorder(true)
+	// Two workers split the queue size, so asset a's shard holds two trades
+	// while the detection queue holds four and never overflows.
+	p := r.start(t, Options{Workers: 2, QueueSize: 4, Overflow: OverflowDropOldest})
+
+	p.Submit(context.Background(), trade("a", 1))
+	<-r.started
+	// The worker is busy with 1, so 2 and 3 fill the shard and each later
+	// trade pushes out the oldest one waiting.
+	for seq := 2; seq <= 5; seq++ {
+		p.Submit(context.Background(), trade("a", seq))
+	}
+	close(r.release)
+	p.Close()
+
+	processed, delivered := r.results()
+	want := []string{"a/1", "a/4", "a/5"}
+	if !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed %v, want %v", processed, want)
+	}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered %v, want %v", delivered, want)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	r := newRecorder(true)
+	p := r.start(t, Options{Workers: 1, QueueSize: 1, Overflow: OverflowBlock})
+
+	p.Submit(context.Background(), trade("a", 1))
+	<-r.started
+	p.Submit(context.Background(), trade("a", 2))
+
+	submitted := make(chan struct{})
+	go func() {
+		p.Submit(context.Background(), trade("a", 3))
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("Submit returned while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(r.release)
+	<-submitted
+	p.Close()
+
+	processed, delivered := r.results()
+	want := []string{"a/1", "a/2", "a/3"}
+	if !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed %v, want %v", processed, want)
+	}
+	if !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered %v, want %v", delivered, want)
+	}
+}
+
+func TestPerAssetOrder(t *testing.T) {
+	r := newRecorder(false)
+	p := r.start(t, Options{Workers: 4, QueueSize: 4096, Overflow: OverflowBlock})
+
+	assets := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	shards := make(map[int]bool)
+	for _, asset := range assets {
+		shards[shard(asset, 4)] = true
+	}
+	if len(shards) < 2 {
+		t.Fatalf("assets %v all map to one shard; pick others", assets)
+	}
+
+	const perAsset = 200
+	for seq := 0; seq < perAsset; seq++ {
+		for _, asset := range assets {
+			p.Submit(context.Background(), trade(asset, seq))
+		}
+	}
+	p.Close()
+
+	processed, delivered := r.results()
+	for name, got := range map[string][]string{"processed": processed, "delivered": delivered} {
+		if len(got) != perAsset*len(assets) {
+			t.Fatalf("%s %d trades, want %d", name, len(got), perAsset*len(assets))
+		}
+		next := make(map[string]int)
+		for _, key := range got {
+			asset, seq, _ := strings.Cut(key, "/")
+			if n, _ := strconv.Atoi(seq); n != next[asset] {
+				t.Fatalf("%s %s/%d after %s/%d", name, asset, n, asset, next[asset]-1)
+			}
+			next[asset]++
+		}
+	}
+}
+
+func TestShardIsStable(t *testing.T) {
+	for _, asset := range []string{"", "a", "71321045679252212594626385532706912750332728571942532289631379312455583992563"} {
+		first := shard(asset, 7)
+		for i := 0; i < 10; i++ {
+			if got := shard(asset, 7); got != first {
+				t.Fatalf("shard(%q) = %d, then %d", asset, first, got)
+			}
+		}
+		if first < 0 || first >= 7 {
+			t.Errorf("shard(%q) = %d, out of range", asset, first)
+		}
+	}
+}
+
+func TestShutdownAbandonsQueue(t *testing.T) {
+	r := newRecorder(true)
+	p := r.start(t, Options{Workers: 1, QueueSize: 8, Overflow: OverflowBlock})
+
+	p.Submit(context.Background(), trade("a", 1))
+	<-r.started
+	for seq := 2; seq <= 4; seq++ {
+		p.Submit(context.Background(), trade("a", seq))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- p.Shutdown(ctx) }()
+
+	// Let the deadline pass before the in-flight trade finishes.
+	<-ctx.Done()
+	time.Sleep(10 * time.Millisecond)
+	close(r.release)
+
+	err := <-errc
+	if err == nil {
+		t.Fatal("Shutdown returned nil after its deadline")
+	}
+	if want := "discarded 3 queued trades"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Shutdown error %q, want it to mention %q", err, want)
+	}
+
+	processed, delivered := r.results()
+	// The trade in flight finishes; its detection and the queued trades
+	// are discarded.
+	if want := []string{"a/1"}; !reflect.DeepEqual(processed, want) {
+		t.Errorf("processed %v, want %v", processed, want)
+	}
+	if len(delivered) != 0 {
+		t.Errorf("delivered %v after abandoning, want nothing", delivered)
+	}
+
+	// Nothing more is taken in or delivered once Shutdown has returned.
+	p.Submit(context.Background(), trade("a", 5))
+	time.Sleep(10 * time.Millisecond)
+	if processed, delivered := r.results(); len(processed) != 1 || len(delivered) != 0 {
+		t.Errorf("after Shutdown: processed %v, delivered %v", processed, delivered)
+	}
+}
+
+func TestShutdownDrainsWithinDeadline(t *testing.T) {
+	r := newRecorder(false)
+	p := r.start(t, Options{Workers: 2, QueueSize: 64, Overflow: OverflowBlock})
+
+	for seq := 0; seq < 20; seq++ {
+		p.Submit(context.Background(), trade("a", seq))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if processed, delivered := r.results(); len(processed) != 20 || len(delivered) != 20 {
+		t.Errorf("processed %d, delivered %d, want 20 each", len(processed), len(delivered))
+	}
+}
@@ -0,0 +1,216 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// Extensions maps each format to the file extension used when writing
+// reports to a directory.
+var Extensions = map[string]string{
+	FormatMarkdown: "md",
+	FormatHTML:     "html",
+	FormatJSON:     "json",
+}
+
+// Title is a one-line heading for the report.
+func (r *Report) Title() string {
+	label := "Daily"
+	if r.Period == Weekly {
+		label = "Weekly"
+	}
+	return fmt.Sprintf("%s Polymarket Report: %s", label, r.End.Format("Jan 02 2006"))
+}
+
+func (r *Report) Render(format string) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return []byte(r.Markdown()), nil
+	case FormatHTML:
+		return r.HTML()
+	case FormatJSON:
+		return json.MarshalIndent(r, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown report format %q (want markdown, html or json)", format)
+	}
+}
+
+func (r *Report) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", r.Title())
+	fmt.Fprintf(&b, "%s to %s · %d events · %d whales\n",
+		r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339), r.Events, r.Whales)
+
+	b.WriteString("\n## Largest trades\n\n")
+	if len(r.LargestTrades) == 0 {
+		b.WriteString("_None_\n")
+	} else {
+		b.WriteString("| Value | Side | Outcome | Price | Market | Trader |\n|---|---|---|---|---|---|\n")
+		for _, t := range r.LargestTrades {
+			fmt.Fprintf(&b, "| %s | %s | %s | %.3f | [%s](%s) | %s |\n",
+				formatUSD(t.UsdValue), strings.ToUpper(t.Side), t.Outcome, t.Price,
+				escape(t.Question), marketURL(t.Slug), escape(traderLabel(t.Trader, t.Wallet)))
+		}
+	}
+
+	b.WriteString("\n## Biggest probability moves\n\n")
+	if len(r.PriceMoves) == 0 {
+		b.WriteString("_None_\n")
+	} else {
+		b.WriteString("| Change | From | To | Outcome | Market |\n|---|---|---|---|---|\n")
+		for _, m := range r.PriceMoves {
+			fmt.Fprintf(&b, "| %s | %.1f%% | %.1f%% | %s | [%s](%s) |\n",
+				formatChange(m.Change), m.From*100, m.To*100, m.Outcome, escape(m.Question), marketURL(m.Slug))
+		}
+	}
+
+	b.WriteString("\n## Whale entries and exits\n\n")
+	if len(r.WhaleTrades) == 0 {
+		b.WriteString("_None_\n")
+	} else {
+		b.WriteString("| Whale | Action | Bought | Sold | Outcome | Market |\n|---|---|---|---|---|---|\n")
+		for _, w := range r.WhaleTrades {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | [%s](%s) |\n",
+				escape(w.Whale), w.Action(), formatUSD(w.BuyUSD), formatUSD(w.SellUSD), w.Outcome,
+				escape(w.Question), marketURL(w.Slug))
+		}
+	}
+
+	b.WriteString("\n## Most active wallets\n\n")
+	if len(r.ActiveWallets) == 0 {
+		b.WriteString("_None_\n")
+	} else {
+		b.WriteString("| Wallet | Trades | Volume |\n|---|---|---|\n")
+		for _, w := range r.ActiveWallets {
+			fmt.Fprintf(&b, "| %s | %d | %s |\n", escape(traderLabel(w.Trader, w.Wallet)), w.Trades, formatUSD(w.UsdValue))
+		}
+	}
+
+	b.WriteString("\n## New markets in watched events\n\n")
+	if len(r.NewMarkets) == 0 {
+		b.WriteString("_None_\n")
+	} else {
+		for _, m := range r.NewMarkets {
+			fmt.Fprintf(&b, "- [%s](%s) in %s (%s)\n", escape(m.Question), marketURL(m.Slug), escape(m.Event), m.CreatedAt.Format("Jan 02 15:04"))
+		}
+	}
+
+	return b.String()
+}
+
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"usd":    formatUSD,
+	"change": formatChange,
+	"pct":    func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	"upper":  strings.ToUpper,
+	"url":    marketURL,
+	"trader": traderLabel,
+	"date":   func(t time.Time) string { return t.Format("Jan 02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1a1a1a; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: 0.3em; }
+.meta { color: #666; }
+table { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+th, td { text-align: left; padding: 0.4em 0.6em; border-bottom: 1px solid #eee; }
+th { background: #f6f6f6; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.buy, .up { color: #0a7d32; }
+.sell, .down { color: #c0262d; }
+.none { color: #999; font-style: italic; }
+a { color: #2456c7; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{date .Start}} to {{date .End}} UTC · {{.Events}} events · {{.Whales}} whales</p>
+
+<h2>Largest trades</h2>
+{{if .LargestTrades}}<table>
+<tr><th>Value</th><th>Side</th><th>Outcome</th><th>Price</th><th>Market</th><th>Trader</th></tr>
+{{range .LargestTrades}}<tr><td class="num">{{usd .UsdValue}}</td><td class="{{.Side}}">{{upper .Side}}</td><td>{{.Outcome}}</td><td class="num">{{printf "%.3f" .Price}}</td><td><a href="{{url .Slug}}">{{.Question}}</a></td><td>{{trader .Trader .Wallet}}</td></tr>
+{{end}}</table>{{else}}<p class="none">None</p>{{end}}
+
+<h2>Biggest probability moves</h2>
+{{if .PriceMoves}}<table>
+<tr><th>Change</th><th>From</th><th>To</th><th>Outcome</th><th>Market</th></tr>
+{{range .PriceMoves}}<tr><td class="num {{if ge .Change 0.0}}up{{else}}down{{end}}">{{change .Change}}</td><td class="num">{{pct .From}}</td><td class="num">{{pct .To}}</td><td>{{.Outcome}}</td><td><a href="{{url .Slug}}">{{.Question}}</a></td></tr>
+{{end}}</table>{{else}}<p class="none">None</p>{{end}}
+
+<h2>Whale entries and exits</h2>
+{{if .WhaleTrades}}<table>
+<tr><th>Whale</th><th>Action</th><th>Bought</th><th>Sold</th><th>Outcome</th><th>Market</th></tr>
+{{range .WhaleTrades}}<tr><td>{{.Whale}}</td><td>{{.Action}}</td><td class="num buy">{{usd .BuyUSD}}</td><td class="num sell">{{usd .SellUSD}}</td><td>{{.Outcome}}</td><td><a href="{{url .Slug}}">{{.Question}}</a></td></tr>
+{{end}}</table>{{else}}<p class="none">None</p>{{end}}
+
+<h2>Most active wallets</h2>
+{{if .ActiveWallets}}<table>
+<tr><th>Wallet</th><th>Trades</th><th>Volume</th></tr>
+{{range .ActiveWallets}}<tr><td>{{trader .Trader .Wallet}}</td><td class="num">{{.Trades}}</td><td class="num">{{usd .UsdValue}}</td></tr>
+{{end}}</table>{{else}}<p class="none">None</p>{{end}}
+
+<h2>New markets in watched events</h2>
+{{if .NewMarkets}}<ul>
+{{range .NewMarkets}}<li><a href="{{url .Slug}}">{{.Question}}</a> in {{.Event}} ({{date .CreatedAt}})</li>
+{{end}}</ul>{{else}}<p class="none">None</p>{{end}}
+</body>
+</html>
+`))
+
+func formatUSD(value float64) string {
+	if value >= 1_000_000 {
+		return fmt.Sprintf("$%.2fM", value/1_000_000)
+	}
+	if value >= 1_000 {
+		return fmt.Sprintf("$%.1fK", value/1_000)
+	}
+	return fmt.Sprintf("$%.2f", value)
+}
+
+// formatChange renders a probability change in percentage points.
+func formatChange(change float64) string {
+	return fmt.Sprintf("%+.1fpp", change*100)
+}
+
+func marketURL(slug string) string {
+	return "https://polymarket.com/event/" + slug
+}
+
+func traderLabel(trader, wallet string) string {
+	if trader != "" {
+		return trader
+	}
+	if len(wallet) > 12 {
+		return wallet[:12] + "..."
+	}
+	return wallet
+}
+
+// escape keeps pipes in market titles from breaking Markdown tables.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,319 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/api"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+const (
+	topN             = 10
+	tradesPerEvent   = 1000
+	whaleActivity    = 500
+	priceFidelityMin = 60
+)
+
+// Report summarizes activity in saved markets and by tracked whales over one
+// period.
+type Report struct {
+	Period        string           `json:"period"`
+	Start         time.Time        `json:"start"`
+	End           time.Time        `json:"end"`
+	Events        int              `json:"events"`
+	Whales        int              `json:"whales"`
+	LargestTrades []Trade          `json:"largestTrades"`
+	PriceMoves    []PriceMove      `json:"priceMoves"`
+	WhaleTrades   []WhaleTrade     `json:"whaleTrades"`
+	ActiveWallets []WalletActivity `json:"activeWallets"`
+	NewMarkets    []NewMarket      `json:"newMarkets"`
+}
+
+type Trade struct {
+	Time     time.Time `json:"time"`
+	Question string    `json:"question"`
+	Slug     string    `json:"slug"`
+	Outcome  string    `json:"outcome"`
+	Side     string    `json:"side"`
+	Price    float64   `json:"price"`
+	Size     float64   `json:"size"`
+	UsdValue float64   `json:"usdValue"`
+	Wallet   string    `json:"wallet"`
+	Trader   string    `json:"trader,omitempty"`
+}
+
+// PriceMove is the change in implied probability of a market's first outcome
+// over the period.
+type PriceMove struct {
+	Question string  `json:"question"`
+	Slug     string  `json:"slug"`
+	Outcome  string  `json:"outcome"`
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+	Change   float64 `json:"change"`
+}
+
+// WhaleTrade aggregates a tracked whale's buys (entries) and sells (exits) in
+// one market outcome.
+type WhaleTrade struct {
+	Whale    string  `json:"whale"`
+	Address  string  `json:"address"`
+	Question string  `json:"question"`
+	Slug     string  `json:"slug"`
+	Outcome  string  `json:"outcome"`
+	BuyUSD   float64 `json:"buyUsd"`
+	SellUSD  float64 `json:"sellUsd"`
+	Trades   int     `json:"trades"`
+}
+
+// Action describes the whale's net direction: entry, exit or both.
+func (w WhaleTrade) Action() string {
+	switch {
+	case w.BuyUSD > 0 && w.SellUSD == 0:
+		return "entry"
+	case w.SellUSD > 0 && w.BuyUSD == 0:
+		return "exit"
+	default:
+		return "both"
+	}
+}
+
+type WalletActivity struct {
+	Wallet   string  `json:"wallet"`
+	Trader   string  `json:"trader,omitempty"`
+	Trades   int     `json:"trades"`
+	UsdValue float64 `json:"usdValue"`
+}
+
+type NewMarket struct {
+	Question  string    `json:"question"`
+	Slug      string    `json:"slug"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PeriodLength returns how far back a report for period looks.
+func PeriodLength(period string) (time.Duration, error) {
+	switch period {
+	case Daily:
+		return 24 * time.Hour, nil
+	case Weekly:
+		return 7 * 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown report period %q (want daily or weekly)", period)
+	}
+}
+
+// Build fetches everything a report needs from the APIs. Markets or whales
+// whose data cannot be fetched are skipped rather than failing the report.
+func Build(client *api.Client, period string, end time.Time, saved []types.SavedMarket, whales []types.Whale) (*Report, error) {
+	length, err := PeriodLength(period)
+	if err != nil {
+		return nil, err
+	}
+	start := end.Add(-length)
+
+	r := &Report{
+		Period: period,
+		Start:  start.UTC(),
+		End:    end.UTC(),
+		Events: len(saved),
+		Whales: len(whales),
+	}
+
+	markets := make(map[string]*types.Market)
+	var trades []Trade
+	wallets := make(map[string]*WalletActivity)
+
+	for _, sm := range saved {
+		event, err := client.GetEventBySlug(sm.Slug)
+		if err != nil {
+			continue
+		}
+
+		var ids []string
+		for i := range event.Markets {
+			m := &event.Markets[i]
+			if m.ConditionID == "" {
+				continue
+			}
+			markets[m.ConditionID] = m
+			ids = append(ids, m.ConditionID)
+
+			if created, err := time.Parse(time.RFC3339, m.CreatedAt); err == nil && !created.Before(start) {
+				r.NewMarkets = append(r.NewMarkets, NewMarket{
+					Question:  m.Question,
+					Slug:      m.Slug,
+					Event:     event.Title,
+					CreatedAt: created.UTC(),
+				})
+			}
+
+			if !m.Closed {
+				if move, ok := priceMove(client, m, start, end); ok {
+					r.PriceMoves = append(r.PriceMoves, move)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+		eventTrades, err := client.GetMarketTrades(ids, tradesPerEvent)
+		if err != nil {
+			continue
+		}
+		for _, t := range eventTrades {
+			ts := time.Unix(t.Timestamp, 0)
+			if ts.Before(start) || ts.After(end) {
+				continue
+			}
+			trader := t.Name
+			if trader == "" {
+				trader = t.Pseudonym
+			}
+			usd := t.Price * t.Size
+			trades = append(trades, Trade{
+				Time:     ts.UTC(),
+				Question: t.Title,
+				Slug:     t.Slug,
+				Outcome:  t.Outcome,
+				Side:     strings.ToLower(t.Side),
+				Price:    t.Price,
+				Size:     t.Size,
+				UsdValue: usd,
+				Wallet:   t.ProxyWallet,
+				Trader:   trader,
+			})
+
+			w := wallets[t.ProxyWallet]
+			if w == nil {
+				w = &WalletActivity{Wallet: t.ProxyWallet, Trader: trader}
+				wallets[t.ProxyWallet] = w
+			}
+			w.Trades++
+			w.UsdValue += usd
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].UsdValue > trades[j].UsdValue })
+	r.LargestTrades = head(trades, topN)
+
+	sort.Slice(r.PriceMoves, func(i, j int) bool { return abs(r.PriceMoves[i].Change) > abs(r.PriceMoves[j].Change) })
+	r.PriceMoves = head(r.PriceMoves, topN)
+
+	for _, w := range wallets {
+		r.ActiveWallets = append(r.ActiveWallets, *w)
+	}
+	sort.Slice(r.ActiveWallets, func(i, j int) bool {
+		if r.ActiveWallets[i].Trades != r.ActiveWallets[j].Trades {
+			return r.ActiveWallets[i].Trades > r.ActiveWallets[j].Trades
+		}
+		return r.ActiveWallets[i].UsdValue > r.ActiveWallets[j].UsdValue
+	})
+	r.ActiveWallets = head(r.ActiveWallets, topN)
+
+	sort.Slice(r.NewMarkets, func(i, j int) bool { return r.NewMarkets[i].CreatedAt.After(r.NewMarkets[j].CreatedAt) })
+
+	for _, whale := range whales {
+		r.WhaleTrades = append(r.WhaleTrades, whaleTrades(client, whale, start, end)...)
+	}
+	sort.Slice(r.WhaleTrades, func(i, j int) bool {
+		return r.WhaleTrades[i].BuyUSD+r.WhaleTrades[i].SellUSD > r.WhaleTrades[j].BuyUSD+r.WhaleTrades[j].SellUSD
+	})
+
+	return r, nil
+}
+
+func priceMove(client *api.Client, m *types.Market, start, end time.Time) (PriceMove, bool) {
+	var tokenIDs, outcomes []string
+	json.Unmarshal([]byte(m.ClobTokens), &tokenIDs)
+	json.Unmarshal([]byte(m.Outcomes), &outcomes)
+	if len(tokenIDs) == 0 {
+		return PriceMove{}, false
+	}
+
+	history, err := client.GetPriceHistory(tokenIDs[0], start, end, priceFidelityMin)
+	if err != nil || len(history) < 2 {
+		return PriceMove{}, false
+	}
+
+	outcome := "Yes"
+	if len(outcomes) > 0 {
+		outcome = outcomes[0]
+	}
+	from := history[0].P
+	to := history[len(history)-1].P
+	return PriceMove{
+		Question: m.Question,
+		Slug:     m.Slug,
+		Outcome:  outcome,
+		From:     from,
+		To:       to,
+		Change:   to - from,
+	}, true
+}
+
+func whaleTrades(client *api.Client, whale types.Whale, start, end time.Time) []WhaleTrade {
+	activity, err := client.GetUserActivity(whale.Address, whaleActivity)
+	if err != nil {
+		return nil
+	}
+
+	byMarket := make(map[string]*WhaleTrade)
+	var order []string
+	for _, a := range activity {
+		ts := time.Unix(a.Timestamp, 0)
+		if a.Type != "TRADE" || ts.Before(start) || ts.After(end) {
+			continue
+		}
+		key := a.ConditionID + "|" + a.Outcome
+		wt := byMarket[key]
+		if wt == nil {
+			wt = &WhaleTrade{
+				Whale:    whale.Name,
+				Address:  whale.Address,
+				Question: a.Title,
+				Slug:     a.EventSlug,
+				Outcome:  a.Outcome,
+			}
+			byMarket[key] = wt
+			order = append(order, key)
+		}
+		wt.Trades++
+		if strings.EqualFold(a.Side, "sell") {
+			wt.SellUSD += a.UsdcSize
+		} else {
+			wt.BuyUSD += a.UsdcSize
+		}
+	}
+
+	result := make([]WhaleTrade, 0, len(order))
+	for _, key := range order {
+		result = append(result, *byMarket[key])
+	}
+	return result
+}
+
+func head[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
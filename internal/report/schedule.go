@@ -0,0 +1,72 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is a recurring local time at which a report is produced: every
+// day, or on one weekday for weekly reports.
+type Schedule struct {
+	Period  string
+	Weekday time.Weekday
+	Hour    int
+	Minute  int
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseSchedule parses "HH:MM" for daily reports and "<weekday> HH:MM"
+// (e.g. "mon 09:00") for weekly ones.
+func ParseSchedule(period, spec string) (Schedule, error) {
+	s := Schedule{Period: period}
+	clock := strings.TrimSpace(spec)
+
+	if period == Weekly {
+		day, rest, ok := strings.Cut(clock, " ")
+		if !ok {
+			return s, fmt.Errorf("weekly schedule %q: want \"<weekday> HH:MM\"", spec)
+		}
+		wd, ok := weekdays[strings.ToLower(day)[:min(3, len(day))]]
+		if !ok {
+			return s, fmt.Errorf("weekly schedule %q: unknown weekday %q", spec, day)
+		}
+		s.Weekday = wd
+		clock = strings.TrimSpace(rest)
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return s, fmt.Errorf("%s schedule %q: want HH:MM", period, spec)
+	}
+	s.Hour, s.Minute = t.Hour(), t.Minute()
+	return s, nil
+}
+
+// Next returns the first scheduled time strictly after now.
+func (s Schedule) Next(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), s.Hour, s.Minute, 0, 0, now.Location())
+
+	if s.Period == Weekly {
+		days := (int(s.Weekday) - int(next.Weekday()) + 7) % 7
+		next = next.AddDate(0, 0, days)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
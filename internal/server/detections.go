@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/notifier"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const defaultDetectionsLimit = 50
+
+// loadDetections seeds the feed from data/detections.jsonl so history
+// survives a restart.
+func loadDetections(f *feed) {
+	lines, err := storage.LoadDetections(len(f.items))
+	if err != nil {
+		logging.For("http").Error("loading detections failed", "err", err)
+		return
+	}
+	for _, line := range lines {
+		var p notifier.Payload
+		if err := json.Unmarshal(line, &p); err != nil {
+			continue
+		}
+		f.publish(p.DetectedAt, p)
+	}
+}
+
+type detectionFilter struct {
+	eventSlug   string
+	conditionID string
+	wallet      string
+	reason      string
+	minSeverity string
+	minUSD      float64
+	whaleOnly   bool
+	since       time.Time
+}
+
+func (f detectionFilter) match(v interface{}) bool {
+	p, ok := v.(notifier.Payload)
+	if !ok {
+		return false
+	}
+	if f.eventSlug != "" && p.EventSlug != f.eventSlug {
+		return false
+	}
+	if f.conditionID != "" && p.ConditionID != f.conditionID {
+		return false
+	}
+	if f.wallet != "" && !strings.EqualFold(p.Wallet, f.wallet) {
+		return false
+	}
+	if f.reason != "" && !contains(p.ReasonTypes, f.reason) {
+		return false
+	}
+	if f.minSeverity != "" && types.SeverityRank(p.Severity) < types.SeverityRank(f.minSeverity) {
+		return false
+	}
+	if p.UsdValue < f.minUSD {
+		return false
+	}
+	if f.whaleOnly && p.WhaleName == "" {
+		return false
+	}
+	if !f.since.IsZero() && p.DetectedAt.Before(f.since) {
+		return false
+	}
+	return true
+}
+
+// parseDetectionFilter reads the filter shared by the detections endpoints
+// from the query string.
+func parseDetectionFilter(r *http.Request) (detectionFilter, error) {
+	q := r.URL.Query()
+	f := detectionFilter{
+		eventSlug:   q.Get("eventSlug"),
+		conditionID: q.Get("conditionId"),
+		wallet:      q.Get("wallet"),
+		reason:      q.Get("reason"),
+		minSeverity: q.Get("severity"),
+	}
+
+	if v := q.Get("minUsd"); v != "" {
+		usd, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return f, errInvalid("minUsd", v)
+		}
+		f.minUSD = usd
+	}
+	if v := q.Get("whale"); v != "" {
+		whale, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errInvalid("whale", v)
+		}
+		f.whaleOnly = whale
+	}
+	if v := q.Get("since"); v != "" {
+		since, err := parseSince(v)
+		if err != nil {
+			return f, errInvalid("since", v)
+		}
+		f.since = since
+	}
+	switch f.minSeverity {
+	case "", types.SeverityInfo, types.SeverityWarning, types.SeverityCritical:
+	default:
+		return f, errInvalid("severity", f.minSeverity)
+	}
+	return f, nil
+}
+
+// parseSince accepts an RFC 3339 time or a duration back from now ("1h").
+func parseSince(v string) (time.Time, error) {
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func (s *Server) handleDetections(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	f, err := parseDetectionFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := defaultDetectionsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, errInvalid("limit", v).Error())
+			return
+		}
+	}
+
+	result := []notifier.Payload{}
+	for _, e := range s.detections.newest(f.match, limit) {
+		result = append(result, e.Payload.(notifier.Payload))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
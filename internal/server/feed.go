@@ -0,0 +1,131 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a stream client may fall behind before
+// it is disconnected. Clients catch up by reconnecting with Last-Event-ID.
+const subscriberBuffer = 256
+
+// event is one item of a feed. IDs increase monotonically and are derived
+// from the event time, so they stay meaningful across restarts for
+// detections reloaded from disk.
+type event struct {
+	ID      int64
+	Time    time.Time
+	Payload interface{}
+}
+
+// feed keeps the most recent events in a fixed-size ring and fans new ones
+// out to stream subscribers.
+type feed struct {
+	mu     sync.RWMutex
+	items  []event
+	next   int
+	full   bool
+	lastID int64
+	subs   map[chan event]struct{}
+}
+
+func newFeed(size int) *feed {
+	return &feed{
+		items: make([]event, size),
+		subs:  make(map[chan event]struct{}),
+	}
+}
+
+// publish appends payload and delivers it to every subscriber. Subscribers
+// that have fallen too far behind are dropped.
+func (f *feed) publish(at time.Time, payload interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := at.UnixMicro()
+	if id <= f.lastID {
+		id = f.lastID + 1
+	}
+	f.lastID = id
+
+	e := event{ID: id, Time: at, Payload: payload}
+	f.items[f.next] = e
+	f.next = (f.next + 1) % len(f.items)
+	if f.next == 0 {
+		f.full = true
+	}
+
+	for ch := range f.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe returns the buffered events after lastID, oldest first, and a
+// channel receiving every later event. Both are taken under one lock so no
+// event is missed or repeated. The channel is closed if the subscriber falls
+// behind.
+func (f *feed) subscribe(lastID int64) ([]event, chan event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replay []event
+	if lastID > 0 {
+		for _, e := range f.ordered() {
+			if e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	ch := make(chan event, subscriberBuffer)
+	f.subs[ch] = struct{}{}
+	return replay, ch
+}
+
+func (f *feed) unsubscribe(ch chan event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subs[ch]; ok {
+		delete(f.subs, ch)
+		close(ch)
+	}
+}
+
+func (f *feed) len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.full {
+		return len(f.items)
+	}
+	return f.next
+}
+
+// newest returns up to limit events matching match, newest first.
+func (f *feed) newest(match func(interface{}) bool, limit int) []event {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	items := f.ordered()
+	var result []event
+	for i := len(items) - 1; i >= 0 && len(result) < limit; i-- {
+		if match(items[i].Payload) {
+			result = append(result, items[i])
+		}
+	}
+	return result
+}
+
+// ordered returns the buffered events oldest first. f.mu must be held.
+func (f *feed) ordered() []event {
+	if !f.full {
+		return f.items[:f.next]
+	}
+	return append(append([]event(nil), f.items[f.next:]...), f.items[:f.next]...)
+}
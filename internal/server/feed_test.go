@@ -0,0 +1,169 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var feedStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fill publishes the payloads 1..n a second apart.
+func fill(f *feed, n int) {
+	for i := 1; i <= n; i++ {
+		f.publish(feedStart.Add(time.Duration(i)*time.Second), i)
+	}
+}
+
+func payloads(events []event) []int {
+	got := []int{}
+	for _, e := range events {
+		got = append(got, e.Payload.(int))
+	}
+	return got
+}
+
+func matchAll(interface{}) bool { return true }
+
+func TestFeedWrapsAround(t *testing.T) {
+	tests := []struct {
+		name      string
+		published int
+		wantLen   int
+		want      []int
+	}{
+		{"empty", 0, 0, []int{}},
+		{"partly full", 3, 3, []int{1, 2, 3}},
+		{"exactly full", 4, 4, []int{1, 2, 3, 4}},
+		{"wrapped once", 6, 4, []int{3, 4, 5, 6}},
+		{"wrapped twice", 9, 4, []int{6, 7, 8, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFeed(4)
+			fill(f, tt.published)
+
+			if got := f.len(); got != tt.wantLen {
+				t.Errorf("len = %d, want %d", got, tt.wantLen)
+			}
+			if got := payloads(f.ordered()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ordered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedNewest(t *testing.T) {
+	f := newFeed(4)
+	fill(f, 6)
+
+	if got, want := payloads(f.newest(matchAll, 10)), []int{6, 5, 4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newest = %v, want %v", got, want)
+	}
+	if got, want := payloads(f.newest(matchAll, 2)), []int{6, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newest limit 2 = %v, want %v", got, want)
+	}
+	odd := func(p interface{}) bool { return p.(int)%2 == 1 }
+	if got, want := payloads(f.newest(odd, 10)), []int{5, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newest odd = %v, want %v", got, want)
+	}
+}
+
+func TestFeedIDsIncrease(t *testing.T) {
+	f := newFeed(8)
+	at := feedStart
+	// Same and earlier times still get increasing IDs.
+	f.publish(at, 1)
+	f.publish(at, 2)
+	f.publish(at.Add(-time.Hour), 3)
+	f.publish(at.Add(time.Second), 4)
+
+	events := f.ordered()
+	if events[0].ID != at.UnixMicro() {
+		t.Errorf("first ID = %d, want the event time %d", events[0].ID, at.UnixMicro())
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].ID <= events[i-1].ID {
+			t.Errorf("ID %d after %d", events[i].ID, events[i-1].ID)
+		}
+	}
+	if events[3].ID != at.Add(time.Second).UnixMicro() {
+		t.Errorf("ID after a later time = %d, want %d", events[3].ID, at.Add(time.Second).UnixMicro())
+	}
+}
+
+func TestFeedReplay(t *testing.T) {
+	f := newFeed(4)
+	fill(f, 6)
+	ids := make(map[int]int64)
+	for _, e := range f.ordered() {
+		ids[e.Payload.(int)] = e.ID
+	}
+
+	tests := []struct {
+		name   string
+		lastID int64
+		want   []int
+	}{
+		{"no last id", 0, []int{}},
+		{"from the middle", ids[4], []int{5, 6}},
+		{"from the newest", ids[6], []int{}},
+		{"from before the oldest kept", ids[3] - 1, []int{3, 4, 5, 6}},
+		{"from an evicted event", feedStart.Add(time.Second).UnixMicro(), []int{3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, ch := f.subscribe(tt.lastID)
+			defer f.unsubscribe(ch)
+			if got := payloads(replay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replay = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedSubscribe(t *testing.T) {
+	f := newFeed(4)
+	fill(f, 2)
+
+	replay, ch := f.subscribe(f.ordered()[0].ID)
+	if got, want := payloads(replay), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("replay = %v, want %v", got, want)
+	}
+
+	f.publish(feedStart.Add(time.Minute), 3)
+	select {
+	case e := <-ch:
+		if e.Payload.(int) != 3 {
+			t.Errorf("received %v, want 3", e.Payload)
+		}
+	default:
+		t.Fatal("published event was not delivered")
+	}
+
+	f.unsubscribe(ch)
+	if _, open := <-ch; open {
+		t.Error("channel still open after unsubscribe")
+	}
+	f.unsubscribe(ch) // a second unsubscribe is harmless
+}
+
+func TestFeedDropsSlowSubscribers(t *testing.T) {
+	f := newFeed(4)
+	_, ch := f.subscribe(0)
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		f.publish(feedStart.Add(time.Duration(i)*time.Second), i)
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events before the channel closed, want %d", n, subscriberBuffer)
+	}
+	if len(f.subs) != 0 {
+		t.Errorf("%d subscribers left, want 0", len(f.subs))
+	}
+}
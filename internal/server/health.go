@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// handleHealthz reports the last health check, failing with 503 while any
+// check fails: the feed is silent, reconnects are exhausted or an API keeps
+// failing.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	st := s.health.Status()
+	code := http.StatusOK
+	if !st.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, st)
+}
+
+// handleReadyz fails with 503 until the WebSocket is connected and every
+// check passes.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+
+	st := s.health.Status()
+	code := http.StatusOK
+	if !st.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, st)
+}
+
+type subscription struct {
+	AssetID       string     `json:"assetId"`
+	ConditionID   string     `json:"conditionId"`
+	EventSlug     string     `json:"eventSlug"`
+	Question      string     `json:"question"`
+	Outcome       string     `json:"outcome"`
+	LastMessageAt *time.Time `json:"lastMessageAt"`
+}
+
+// handleSubscriptions lists subscribed assets with when each was last seen
+// on the feed, longest silent first.
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	last := s.ws.LastMessages()
+	subs := make([]subscription, 0, len(last))
+	for id, at := range last {
+		sub := subscription{AssetID: id}
+		if market, outcome := s.detector.MarketForAsset(id); market != nil {
+			sub.ConditionID = market.ConditionID
+			sub.EventSlug = market.EventSlug()
+			sub.Question = market.Question
+			sub.Outcome = outcome
+		}
+		if !at.IsZero() {
+			at := at.UTC()
+			sub.LastMessageAt = &at
+		}
+		subs = append(subs, sub)
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		a, b := subs[i].LastMessageAt, subs[j].LastMessageAt
+		if a == nil || b == nil {
+			if a == nil && b == nil {
+				return subs[i].AssetID < subs[j].AssetID
+			}
+			return a == nil
+		}
+		return a.Before(*b)
+	})
+	writeJSON(w, http.StatusOK, subs)
+}
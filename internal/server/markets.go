@@ -0,0 +1,229 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+var eventURLRe = regexp.MustCompile(`polymarket\.com/event/([a-z0-9-]+)`)
+
+type addMarketRequest struct {
+	// Slug is an event slug or a polymarket.com event URL.
+	Slug string `json:"slug"`
+}
+
+type marketResponse struct {
+	types.SavedMarket
+	Markets []types.MarketStats `json:"markets"`
+}
+
+// GET lists saved markets; POST saves and starts watching an event.
+func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		markets, err := storage.LoadMarkets()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, markets)
+		return
+	}
+
+	var req addMarketRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	slug := req.Slug
+	if m := eventURLRe.FindStringSubmatch(slug); len(m) >= 2 {
+		slug = m[1]
+	}
+	if slug == "" {
+		writeError(w, http.StatusBadRequest, "missing slug")
+		return
+	}
+
+	event, err := s.api.GetEventBySlug(slug)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	saved := types.SavedMarket{
+		Slug:    event.Slug,
+		Title:   event.Title,
+		AddedAt: time.Now().Format(time.RFC3339),
+	}
+
+	// Markets nested in an event don't carry their parent, which
+	// EventSlug relies on.
+	for i := range event.Markets {
+		if len(event.Markets[i].Events) == 0 {
+			event.Markets[i].Events = []types.MarketEvent{{Slug: event.Slug, Title: event.Title}}
+		}
+	}
+
+	s.mu.Lock()
+	added, err := storage.AddMarket(saved)
+	if err == nil {
+		s.watch(event.Markets)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	code := http.StatusOK
+	if added {
+		code = http.StatusCreated
+		logging.For("http").Info("market added", "slug", saved.Slug)
+	}
+	writeJSON(w, code, marketResponse{SavedMarket: saved, Markets: s.eventStats(event.Markets)})
+}
+
+// GET shows a saved market with live stats; DELETE stops watching it.
+func (s *Server) handleMarket(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodDelete) {
+		return
+	}
+
+	slug, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/markets/"))
+	if err != nil || slug == "" {
+		writeError(w, http.StatusNotFound, "market not found")
+		return
+	}
+
+	saved, err := findMarket(slug)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if saved == nil {
+		writeError(w, http.StatusNotFound, "market not found")
+		return
+	}
+
+	// The event's markets are needed either way: for their stats, or to
+	// know which assets to unsubscribe.
+	event, err := s.api.GetEventBySlug(slug)
+	if err != nil && r.Method == http.MethodGet {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, marketResponse{SavedMarket: *saved, Markets: s.eventStats(event.Markets)})
+		return
+	}
+
+	if event == nil {
+		logging.For("http").Warn("event lookup failed, its markets stay watched until restart", "slug", slug, "err", err)
+	}
+
+	s.mu.Lock()
+	_, err = storage.RemoveMarket(slug)
+	if err == nil && event != nil {
+		s.unwatch(event.Markets)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logging.For("http").Info("market removed", "slug", slug)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET lists live stats for every watched market, optionally filtered by
+// eventSlug.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	eventSlug := r.URL.Query().Get("eventSlug")
+	stats := []types.MarketStats{}
+	for _, st := range s.detector.Stats() {
+		if eventSlug == "" || st.EventSlug == eventSlug {
+			stats = append(stats, st)
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// GET shows live stats for one watched market by condition ID.
+func (s *Server) handleMarketStats(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/stats/")
+	for _, st := range s.detector.Stats() {
+		if st.ConditionID == id {
+			writeJSON(w, http.StatusOK, st)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "market not watched")
+}
+
+// watch adds markets to the detector and subscribes to their assets.
+func (s *Server) watch(markets []types.Market) {
+	var tradable []types.Market
+	for _, m := range markets {
+		if m.ConditionID != "" && m.ClobTokens != "" {
+			tradable = append(tradable, m)
+		}
+	}
+	s.ws.Subscribe(s.detector.AddMarkets(tradable))
+}
+
+// unwatch removes markets from the detector and unsubscribes their assets.
+func (s *Server) unwatch(markets []types.Market) {
+	ids := make([]string, 0, len(markets))
+	for _, m := range markets {
+		ids = append(ids, m.ConditionID)
+	}
+	s.ws.Unsubscribe(s.detector.RemoveMarkets(ids))
+}
+
+// eventStats returns the live stats of the given markets that are watched.
+func (s *Server) eventStats(markets []types.Market) []types.MarketStats {
+	ids := make(map[string]bool, len(markets))
+	for _, m := range markets {
+		ids[m.ConditionID] = true
+	}
+
+	stats := []types.MarketStats{}
+	for _, st := range s.detector.Stats() {
+		if ids[st.ConditionID] {
+			stats = append(stats, st)
+		}
+	}
+	return stats
+}
+
+func findMarket(slug string) (*types.SavedMarket, error) {
+	markets, err := storage.LoadMarkets()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range markets {
+		if m.Slug == slug {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/api"
+	"github.com/mikefdy/polymarket-tool/internal/detector"
+	"github.com/mikefdy/polymarket-tool/internal/health"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/notifier"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+	"github.com/mikefdy/polymarket-tool/internal/ws"
+)
+
+// maxDetections and maxTrades are how many recent events are kept in memory
+// for queries and stream replay.
+const (
+	maxDetections = 1000
+	maxTrades     = 5000
+)
+
+// Server is the JSON API exposed by `start --http`. It reads from and makes
+// changes to the running detector and WebSocket client, so edits take effect
+// without a restart.
+type Server struct {
+	api        *api.Client
+	detector   *detector.Detector
+	ws         *ws.Client
+	notify     *notifier.Notifier
+	health     *health.Monitor
+	detections *feed
+	trades     *feed
+	started    time.Time
+
+	// mu serializes changes to saved markets and whales so the storage
+	// files and the detector stay in step.
+	mu sync.Mutex
+
+	// token, when set, must be sent as a bearer token to change anything.
+	token string
+
+	httpMu sync.Mutex
+	http   *http.Server
+	stop   context.CancelFunc
+}
+
+func New(apiClient *api.Client, detect *detector.Detector, wsClient *ws.Client, notify *notifier.Notifier, monitor *health.Monitor) *Server {
+	s := &Server{
+		api:        apiClient,
+		detector:   detect,
+		ws:         wsClient,
+		notify:     notify,
+		health:     monitor,
+		detections: newFeed(maxDetections),
+		trades:     newFeed(maxTrades),
+		started:    time.Now(),
+	}
+	loadDetections(s.detections)
+	return s
+}
+
+// SetToken requires token as a bearer token on requests that change markets
+// or whales. Empty leaves them open.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// RecordDetection makes a detection available through the API and streams.
+func (s *Server) RecordDetection(p notifier.Payload) {
+	s.detections.publish(p.DetectedAt, p)
+}
+
+// RecordTrade makes a trade available through the trade stream.
+func (s *Server) RecordTrade(t types.MarketTrade) {
+	s.trades.publish(t.ReceivedAt, notifier.NewTradePayload(t))
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/subscriptions", s.handleSubscriptions)
+	mux.HandleFunc("/api/detections", s.handleDetections)
+	mux.HandleFunc("/api/markets", s.handleMarkets)
+	mux.HandleFunc("/api/markets/", s.handleMarket)
+	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/stats/", s.handleMarketStats)
+	mux.HandleFunc("/api/whales", s.handleWhales)
+	mux.HandleFunc("/api/whales/", s.handleWhale)
+	mux.HandleFunc("/stream/detections", s.handleDetectionStream)
+	mux.HandleFunc("/stream/trades", s.handleTradeStream)
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/", dashboardHandler())
+	return s.authorize(mux)
+}
+
+// ListenAndServe serves the API on addr until Shutdown is called or the
+// listener fails.
+func (s *Server) ListenAndServe(addr string) error {
+	// Requests share a context cancelled by Shutdown, which ends the
+	// long-lived streams that would otherwise hold it up.
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	s.httpMu.Lock()
+	s.http, s.stop = srv, cancel
+	s.httpMu.Unlock()
+
+	logging.For("http").Info("listening", "addr", addr)
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections, ends streams and waits for requests
+// in progress until ctx ends, then closes whatever is left.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.httpMu.Lock()
+	srv, stop := s.http, s.stop
+	s.httpMu.Unlock()
+	if srv == nil {
+		return nil
+	}
+
+	stop()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		return err
+	}
+	return nil
+}
+
+type status struct {
+	StartedAt      time.Time `json:"startedAt"`
+	Uptime         string    `json:"uptime"`
+	WS             ws.Status `json:"ws"`
+	Healthy        bool      `json:"healthy"`
+	WatchedMarkets int       `json:"watchedMarkets"`
+	Detections     int       `json:"detections"`
+	Sinks          []string  `json:"sinks"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status{
+		StartedAt:      s.started.UTC(),
+		Uptime:         time.Since(s.started).Round(time.Second).String(),
+		WS:             s.ws.Status(),
+		Healthy:        s.health.Status().Healthy,
+		WatchedMarkets: len(s.detector.GetWatchedConditionIDs()),
+		Detections:     s.detections.len(),
+		Sinks:          s.notify.Sinks(),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.For("http").Warn("response write failed", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
+
+// allowMethods rejects requests whose method is not listed.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+func errInvalid(param, value string) error {
+	return fmt.Errorf("invalid %s %q", param, value)
+}
+
+// decodeBody decodes a JSON request body into v, or writes the error and
+// returns false. Only application/json is accepted: browsers send that
+// cross-site only after a CORS preflight, which the API never approves, so
+// other sites can't make changes through a visitor's browser.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return false
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// authorize requires the bearer token, when one is set, on every request
+// that can change something. Reads stay open.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="polymarket-tool"`)
+				writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
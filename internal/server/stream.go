@@ -0,0 +1,246 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/notifier"
+)
+
+const (
+	streamHeartbeat = 15 * time.Second
+	streamWriteWait = 10 * time.Second
+)
+
+// upgrader keeps the default origin check: a browser may only open a stream
+// from a page served by the API itself, whose Origin matches the Host.
+// Requests without an Origin come from other clients and are let through.
+var upgrader = websocket.Upgrader{}
+
+// streamMessage is the envelope for events sent over WebSocket, mirroring
+// the SSE id/event/data fields.
+type streamMessage struct {
+	ID    int64       `json:"id"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
+}
+
+type tradeFilter struct {
+	eventSlug   string
+	conditionID string
+	minUSD      float64
+}
+
+func (f tradeFilter) match(v interface{}) bool {
+	p, ok := v.(notifier.TradePayload)
+	if !ok {
+		return false
+	}
+	if f.eventSlug != "" && p.EventSlug != f.eventSlug {
+		return false
+	}
+	if f.conditionID != "" && p.ConditionID != f.conditionID {
+		return false
+	}
+	return p.UsdValue >= f.minUSD
+}
+
+func (s *Server) handleDetectionStream(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	f, err := parseDetectionFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.serveStream(w, r, s.detections, "detection", f.match)
+}
+
+func (s *Server) handleTradeStream(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("whale") != "" {
+		// Trades from the market feed don't identify the trader.
+		writeError(w, http.StatusBadRequest, "whale filter is only available for detections")
+		return
+	}
+	f := tradeFilter{eventSlug: q.Get("eventSlug"), conditionID: q.Get("conditionId")}
+	if v := q.Get("minUsd"); v != "" {
+		usd, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errInvalid("minUsd", v).Error())
+			return
+		}
+		f.minUSD = usd
+	}
+	s.serveStream(w, r, s.trades, "trade", f.match)
+}
+
+// serveStream sends events from feed as Server-Sent Events, or over a
+// WebSocket when the request asks for an upgrade. Events buffered after the
+// client's Last-Event-ID are replayed first.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, feed *feed, name string, match func(interface{}) bool) {
+	lastID, err := lastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already written an error response.
+			return
+		}
+		replay, ch := feed.subscribe(lastID)
+		defer feed.unsubscribe(ch)
+		serveWebSocket(conn, name, replay, ch, match)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	replay, ch := feed.subscribe(lastID)
+	defer feed.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	send := func(e event) bool {
+		if !match(e.Payload) {
+			return true
+		}
+		data, err := json.Marshal(e.Payload)
+		if err != nil {
+			return true
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, name, data)
+		flusher.Flush()
+		return err == nil
+	}
+
+	for _, e := range replay {
+		if !send(e) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				// Fell behind; the client resumes from its Last-Event-ID.
+				return
+			}
+			if !send(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func serveWebSocket(conn *websocket.Conn, name string, replay []event, ch chan event, match func(interface{}) bool) {
+	defer conn.Close()
+
+	// Drain incoming frames so pongs and the close handshake are processed;
+	// a read error means the client went away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(e event) bool {
+		if !match(e.Payload) {
+			return true
+		}
+		conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+		if err := conn.WriteJSON(streamMessage{ID: e.ID, Event: name, Data: e.Payload}); err != nil {
+			logging.For("http").Debug("stream write failed", "err", err)
+			return false
+		}
+		return true
+	}
+
+	for _, e := range replay {
+		if !send(e) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case e, ok := <-ch:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"),
+					time.Now().Add(streamWriteWait))
+				return
+			}
+			if !send(e) {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// lastEventID reads the resume point from the Last-Event-ID header that
+// EventSource sends on reconnect, or the lastEventId query parameter for
+// WebSocket clients, which cannot set headers.
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("lastEventId")
+	}
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errInvalid("Last-Event-ID", v)
+	}
+	return id, nil
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// web holds the dashboard: static files that only talk to the JSON API and
+// streams, so anything it shows is also available to other clients.
+//
+//go:embed web
+var web embed.FS
+
+func dashboardHandler() http.Handler {
+	root, err := fs.Sub(web, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(root))
+}
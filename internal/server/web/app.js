@@ -0,0 +1,300 @@
+"use strict";
+
+// Dashboard for `polymarket-tool start --http`. Everything here comes from
+// the JSON API and streams served by the same process.
+
+const MAX_FEED_ROWS = 200;
+const POLL_MS = 5000;
+
+const $ = (sel) => document.querySelector(sel);
+
+// api calls the JSON API. When the server has HTTP_TOKEN set, changes need
+// it as a bearer token: the first refusal asks for it and it is remembered.
+async function api(method, path, body, retried) {
+  const opts = { method, headers: {} };
+  const token = localStorage.getItem("apiToken");
+  if (token) opts.headers["Authorization"] = `Bearer ${token}`;
+  if (body !== undefined) {
+    opts.headers["Content-Type"] = "application/json";
+    opts.body = JSON.stringify(body);
+  }
+  const res = await fetch(path, opts);
+  if (res.status === 401 && !retried) {
+    const entered = window.prompt("API token (HTTP_TOKEN)");
+    if (entered) {
+      localStorage.setItem("apiToken", entered);
+      return api(method, path, body, true);
+    }
+  }
+  if (res.status === 204) return null;
+  const data = await res.json().catch(() => null);
+  if (!res.ok) throw new Error((data && data.error) || res.statusText);
+  return data;
+}
+
+function el(tag, props, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, props || {});
+  for (const child of children) {
+    if (child === null || child === undefined) continue;
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function usd(v) {
+  const abs = Math.abs(v);
+  const sign = v < 0 ? "-" : "";
+  if (abs >= 1e6) return `${sign}$${(abs / 1e6).toFixed(2)}M`;
+  if (abs >= 1e3) return `${sign}$${(abs / 1e3).toFixed(1)}K`;
+  return `${sign}$${abs.toFixed(0)}`;
+}
+
+function ago(ts) {
+  const t = new Date(ts).getTime();
+  if (!t || t < 0) return "—";
+  const s = Math.max(0, Math.round((Date.now() - t) / 1000));
+  if (s < 60) return `${s}s ago`;
+  if (s < 3600) return `${Math.floor(s / 60)}m ago`;
+  if (s < 86400) return `${Math.floor(s / 3600)}h ago`;
+  return `${Math.floor(s / 86400)}d ago`;
+}
+
+function toast(msg) {
+  const t = $("#toast");
+  t.textContent = msg;
+  t.hidden = false;
+  clearTimeout(toast.timer);
+  toast.timer = setTimeout(() => { t.hidden = true; }, 4000);
+}
+
+function marketLink(slug, text) {
+  return el("a", { href: `https://polymarket.com/event/${encodeURIComponent(slug)}`, target: "_blank", rel: "noopener" }, text);
+}
+
+// ============= STATUS =============
+
+async function refreshStatus() {
+  try {
+    const s = await api("GET", "/api/status");
+    const dot = el("span", { className: "dot" + (s.ws.connected ? " up" : "") });
+    $("#status").replaceChildren(
+      dot,
+      `${s.ws.connected ? "connected" : "disconnected"} · ${s.ws.subscribedAssets} assets · ` +
+      `${s.watchedMarkets} markets · last message ${ago(s.ws.lastMessageAt)} · up ${s.uptime}`
+    );
+  } catch (err) {
+    $("#status").textContent = `unreachable: ${err.message}`;
+  }
+}
+
+// ============= DETECTION FEED =============
+
+let source = null;
+
+function feedQuery() {
+  const form = $("#feed-filter");
+  const params = new URLSearchParams();
+  if (form.minUsd.value) params.set("minUsd", form.minUsd.value);
+  if (form.whale.checked) params.set("whale", "true");
+  return params;
+}
+
+function detectionRow(d, fresh) {
+  const reason = d.whaleName ? `🐋 ${d.whaleName} · ${d.reasonTypes.join(", ")}` : d.reasonTypes.join(", ");
+  const classes = [d.severity];
+  if (d.muted) classes.push("muted");
+  if (fresh) classes.push("new");
+  return el("tr", { className: classes.join(" "), title: d.reason },
+    el("td", null, new Date(d.detectedAt).toLocaleTimeString()),
+    el("td", { className: "wrap" }, marketLink(d.eventSlug, d.question), el("span", { className: "muted-text" }, ` ${d.outcome}`)),
+    el("td", { className: String(d.side).toLowerCase() }, String(d.side).toUpperCase()),
+    el("td", { className: "num" }, `${(d.price * 100).toFixed(1)}¢`),
+    el("td", { className: "num" }, usd(d.usdValue)),
+    el("td", null, reason)
+  );
+}
+
+function addDetection(d, fresh) {
+  const feed = $("#feed");
+  feed.prepend(detectionRow(d, fresh));
+  while (feed.children.length > MAX_FEED_ROWS) feed.lastChild.remove();
+}
+
+async function startFeed() {
+  if (source) source.close();
+  const params = feedQuery();
+
+  const recent = new URLSearchParams(params);
+  recent.set("limit", "50");
+  try {
+    const detections = await api("GET", `/api/detections?${recent}`);
+    $("#feed").replaceChildren();
+    for (const d of detections.reverse()) addDetection(d, false);
+  } catch (err) {
+    toast(`Loading detections failed: ${err.message}`);
+  }
+
+  // EventSource reconnects by itself and resumes from the last event ID.
+  source = new EventSource(`/stream/detections?${params}`);
+  source.addEventListener("detection", (e) => addDetection(JSON.parse(e.data), true));
+}
+
+// ============= MARKETS =============
+
+let stats = [];
+
+function renderMarkets() {
+  const q = $("#markets-search").value.toLowerCase();
+  const rows = stats
+    .filter((m) => !q || m.question.toLowerCase().includes(q) || m.eventSlug.includes(q))
+    .map((m) => {
+      const net = m.buyUsd - m.sellUsd;
+      return el("tr", null,
+        el("td", { className: "wrap" }, marketLink(m.eventSlug, m.question)),
+        el("td", { className: "num" }, m.trades ? `${(m.lastPrice * 100).toFixed(1)}¢ ${m.lastOutcome || ""}` : "—"),
+        el("td", { className: "num" }, m.trades),
+        el("td", { className: "num" }, usd(m.volumeUsd)),
+        el("td", { className: "num " + (net > 0 ? "buy" : net < 0 ? "sell" : "") }, usd(net)),
+        el("td", { className: "num" }, m.detections),
+        el("td", null, m.trades ? ago(m.lastTradeAt) : "—")
+      );
+    });
+  $("#markets").replaceChildren(...rows);
+}
+
+async function refreshMarkets() {
+  try {
+    stats = await api("GET", "/api/stats");
+    renderMarkets();
+  } catch (err) {
+    toast(`Loading markets failed: ${err.message}`);
+  }
+}
+
+async function refreshSaved() {
+  try {
+    const saved = await api("GET", "/api/markets");
+    $("#saved").replaceChildren(...saved.map((m) => {
+      const remove = el("button", { className: "remove", title: "Remove", textContent: "×" });
+      remove.onclick = async () => {
+        if (!confirm(`Stop watching ${m.title}?`)) return;
+        try {
+          await api("DELETE", `/api/markets/${encodeURIComponent(m.slug)}`);
+          refreshSaved();
+          refreshMarkets();
+        } catch (err) {
+          toast(err.message);
+        }
+      };
+      return el("li", null,
+        el("div", { className: "row" }, marketLink(m.slug, m.title), remove),
+        el("div", { className: "sub" }, m.slug)
+      );
+    }));
+  } catch (err) {
+    toast(`Loading saved events failed: ${err.message}`);
+  }
+}
+
+$("#add-market").onsubmit = async (e) => {
+  e.preventDefault();
+  const form = e.target;
+  try {
+    await api("POST", "/api/markets", { slug: form.slug.value.trim() });
+    form.reset();
+    refreshSaved();
+    refreshMarkets();
+  } catch (err) {
+    toast(`Adding market failed: ${err.message}`);
+  }
+};
+
+// ============= WHALES =============
+
+async function toggleActivity(li, address) {
+  const existing = li.querySelector(".activity");
+  if (existing) {
+    existing.remove();
+    return;
+  }
+  const box = el("div", { className: "activity muted-text" }, "loading…");
+  li.append(box);
+  try {
+    const activity = await api("GET", `/api/whales/${address}/activity?limit=5`);
+    if (!activity.length) {
+      box.textContent = "no recent activity";
+      return;
+    }
+    box.replaceChildren(...activity.map((a) => el("div", null,
+      el("span", { className: String(a.side).toLowerCase() }, a.side || a.type), " ",
+      usd(a.usdcSize), " ", a.outcome || "", " · ", marketLink(a.eventSlug || a.slug, a.title),
+      el("span", { className: "muted-text" }, ` ${ago(a.timestamp * 1000)}`)
+    )));
+  } catch (err) {
+    box.textContent = err.message;
+  }
+}
+
+async function refreshWhales() {
+  try {
+    const whales = await api("GET", "/api/whales");
+    $("#whales").replaceChildren(...whales.map((w) => {
+      const li = el("li");
+      const name = el("a", { href: "#", textContent: w.name || w.address.slice(0, 10) });
+      name.onclick = (e) => {
+        e.preventDefault();
+        toggleActivity(li, w.address);
+      };
+      const remove = el("button", { className: "remove", title: "Remove", textContent: "×" });
+      remove.onclick = async () => {
+        if (!confirm(`Stop tracking ${w.name || w.address}?`)) return;
+        try {
+          await api("DELETE", `/api/whales/${w.address}`);
+          refreshWhales();
+        } catch (err) {
+          toast(err.message);
+        }
+      };
+      const sub = [w.address.slice(0, 10) + "…"];
+      if (w.pnl) sub.push(`PnL ${usd(w.pnl)}`);
+      if (w.note) sub.push(w.note);
+      li.append(
+        el("div", { className: "row" }, name, remove),
+        el("div", { className: "sub" }, sub.join(" · "))
+      );
+      return li;
+    }));
+  } catch (err) {
+    toast(`Loading whales failed: ${err.message}`);
+  }
+}
+
+$("#add-whale").onsubmit = async (e) => {
+  e.preventDefault();
+  const form = e.target;
+  try {
+    await api("POST", "/api/whales", { address: form.address.value.trim(), name: form.elements.namedItem("name").value.trim() });
+    form.reset();
+    refreshWhales();
+  } catch (err) {
+    toast(`Adding whale failed: ${err.message}`);
+  }
+};
+
+// ============= STARTUP =============
+
+$("#feed-filter").onsubmit = (e) => {
+  e.preventDefault();
+  startFeed();
+};
+$("#markets-search").oninput = renderMarkets;
+
+refreshStatus();
+refreshMarkets();
+refreshSaved();
+refreshWhales();
+startFeed();
+
+setInterval(refreshStatus, POLL_MS);
+setInterval(refreshMarkets, POLL_MS);
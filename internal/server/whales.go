@@ -0,0 +1,197 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/types"
+)
+
+const defaultActivityLimit = 20
+
+var addressRe = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+type whaleRequest struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Note    string `json:"note"`
+}
+
+// GET lists whales; POST adds one.
+func (s *Server) handleWhales(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		whales, err := storage.LoadWhales()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, whales)
+		return
+	}
+
+	var req whaleRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !addressRe.MatchString(req.Address) {
+		writeError(w, http.StatusBadRequest, errInvalid("address", req.Address).Error())
+		return
+	}
+
+	whale := types.Whale{
+		Address: req.Address,
+		Name:    req.Name,
+		Note:    req.Note,
+		AddedAt: time.Now().Format(time.RFC3339),
+	}
+
+	s.mu.Lock()
+	added, err := storage.AddWhale(whale)
+	if err == nil && added {
+		err = s.reloadWhales()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !added {
+		writeError(w, http.StatusConflict, "whale already tracked")
+		return
+	}
+	logging.For("http").Info("whale added", "address", whale.Address)
+	writeJSON(w, http.StatusCreated, whale)
+}
+
+// GET shows a whale; PUT updates its name and note; DELETE stops tracking it.
+// GET .../activity lists the whale's recent trades.
+func (s *Server) handleWhale(w http.ResponseWriter, r *http.Request) {
+	address := strings.TrimPrefix(r.URL.Path, "/api/whales/")
+	if a, ok := strings.CutSuffix(address, "/activity"); ok {
+		s.handleWhaleActivity(w, r, a)
+		return
+	}
+
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		whale, err := findWhale(address)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if whale == nil {
+			writeError(w, http.StatusNotFound, "whale not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, whale)
+
+	case http.MethodPut:
+		var req whaleRequest
+		if !decodeBody(w, r, &req) {
+			return
+		}
+
+		s.mu.Lock()
+		found, err := storage.UpdateWhale(types.Whale{Address: address, Name: req.Name, Note: req.Note})
+		if err == nil && found {
+			err = s.reloadWhales()
+		}
+		s.mu.Unlock()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "whale not found")
+			return
+		}
+
+		whale, err := findWhale(address)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, whale)
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		found, err := storage.RemoveWhale(address)
+		if err == nil && found {
+			err = s.reloadWhales()
+		}
+		s.mu.Unlock()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "whale not found")
+			return
+		}
+		logging.For("http").Info("whale removed", "address", address)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleWhaleActivity(w http.ResponseWriter, r *http.Request, address string) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	limit := defaultActivityLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, errInvalid("limit", v).Error())
+			return
+		}
+		limit = n
+	}
+
+	activity, err := s.api.GetUserActivity(address, limit)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	if activity == nil {
+		activity = []types.UserActivity{}
+	}
+	writeJSON(w, http.StatusOK, activity)
+}
+
+// reloadWhales pushes the saved whale list to the running detector.
+func (s *Server) reloadWhales() error {
+	whales, err := storage.LoadWhales()
+	if err != nil {
+		return err
+	}
+	s.detector.SetWhales(whales)
+	return nil
+}
+
+func findWhale(address string) (*types.Whale, error) {
+	whales, err := storage.LoadWhales()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range whales {
+		if strings.EqualFold(w.Address, address) {
+			return &w, nil
+		}
+	}
+	return nil, nil
+}
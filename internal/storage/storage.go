@@ -1,20 +1,58 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mikefdy/polymarket-tool/internal/types"
 )
 
-const dataDir = "data"
+// DefaultDir is where data files are kept unless SetDir says otherwise.
+const DefaultDir = "data"
+
+var dataDir = DefaultDir
 
 func ensureDataDir() error {
 	return os.MkdirAll(dataDir, 0755)
 }
 
+// Dir returns the directory the data files are kept in.
+func Dir() string {
+	return dataDir
+}
+
+// SetDir moves the data files to dir. It must be called before any file is
+// read or written.
+func SetDir(dir string) {
+	dataDir = dir
+}
+
+// CheckWritable creates the data directory if needed and writes and removes
+// a scratch file in it.
+func CheckWritable() error {
+	if err := ensureDataDir(); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dataDir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_, err = f.WriteString("ok")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if rerr := os.Remove(name); err == nil {
+		err = rerr
+	}
+	return err
+}
+
 func LoadWhales() ([]types.Whale, error) {
 	if err := ensureDataDir(); err != nil {
 		return nil, err
@@ -65,6 +103,24 @@ func AddWhale(whale types.Whale) (bool, error) {
 	return true, SaveWhales(whales)
 }
 
+// UpdateWhale replaces the name and note of the whale with whale.Address,
+// keeping its stats and AddedAt.
+func UpdateWhale(whale types.Whale) (bool, error) {
+	whales, err := LoadWhales()
+	if err != nil {
+		return false, err
+	}
+
+	for i, w := range whales {
+		if strings.EqualFold(w.Address, whale.Address) {
+			whales[i].Name = whale.Name
+			whales[i].Note = whale.Note
+			return true, SaveWhales(whales)
+		}
+	}
+	return false, nil
+}
+
 func RemoveWhale(address string) (bool, error) {
 	whales, err := LoadWhales()
 	if err != nil {
@@ -178,3 +234,331 @@ func ClearWhales() (int, error) {
 	count := len(whales)
 	return count, SaveWhales([]types.Whale{})
 }
+
+func LoadMutes() ([]types.Mute, error) {
+	if err := ensureDataDir(); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dataDir, "mutes.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.Mute{}, nil
+		}
+		return nil, err
+	}
+
+	var mutes []types.Mute
+	if err := json.Unmarshal(data, &mutes); err != nil {
+		return nil, err
+	}
+	return mutes, nil
+}
+
+func SaveMutes(mutes []types.Mute) error {
+	if err := ensureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(mutes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dataDir, "mutes.json"), data, 0644)
+}
+
+// AddMute adds or replaces the mute for the same kind and target, dropping
+// expired mutes along the way.
+func AddMute(mute types.Mute) error {
+	mutes, err := LoadMutes()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filtered := make([]types.Mute, 0, len(mutes)+1)
+	for _, m := range mutes {
+		if m.Expired(now) || (m.Kind == mute.Kind && strings.EqualFold(m.Target, mute.Target)) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+
+	return SaveMutes(append(filtered, mute))
+}
+
+func RemoveMute(kind, target string) (bool, error) {
+	mutes, err := LoadMutes()
+	if err != nil {
+		return false, err
+	}
+
+	filtered := make([]types.Mute, 0, len(mutes))
+	found := false
+	for _, m := range mutes {
+		if m.Kind == kind && strings.EqualFold(m.Target, target) {
+			found = true
+		} else {
+			filtered = append(filtered, m)
+		}
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	return true, SaveMutes(filtered)
+}
+
+// DefaultDetectionsMaxSize is the size data/detections.jsonl may grow to
+// before AppendDetection rotates it.
+const DefaultDetectionsMaxSize = 64 << 20
+
+const detectionsFile = "detections.jsonl"
+
+var (
+	detectionsMu      sync.Mutex
+	detectionsMaxSize int64 = DefaultDetectionsMaxSize
+)
+
+// SetDetectionsMaxSize sets the size, in bytes, at which the detection log is
+// rotated. Zero or less restores the default.
+func SetDetectionsMaxSize(n int64) {
+	if n <= 0 {
+		n = DefaultDetectionsMaxSize
+	}
+	detectionsMu.Lock()
+	detectionsMaxSize = n
+	detectionsMu.Unlock()
+}
+
+// AppendDetection records a detection as one JSON line in
+// data/detections.jsonl. When the line would take the file past the maximum
+// size, the file is first renamed to detections.jsonl.1, replacing the
+// previous one, so the log never takes more than twice the maximum.
+func AppendDetection(record interface{}) error {
+	if err := ensureDataDir(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	detectionsMu.Lock()
+	defer detectionsMu.Unlock()
+
+	path := filepath.Join(dataDir, detectionsFile)
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && info.Size()+int64(len(data)) > detectionsMaxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LoadDetections returns the last limit lines of the detection log, oldest
+// first, for the caller to decode, reading from the rotated file when the
+// current one is short. Only the tail needed is read; a limit of zero or less
+// reads everything.
+func LoadDetections(limit int) ([][]byte, error) {
+	path := filepath.Join(dataDir, detectionsFile)
+	lines, err := tailLines(path, limit)
+	if err != nil || (limit > 0 && len(lines) >= limit) {
+		return lines, err
+	}
+
+	rest := 0
+	if limit > 0 {
+		rest = limit - len(lines)
+	}
+	older, err := tailLines(path+".1", rest)
+	if err != nil {
+		return nil, err
+	}
+	return append(older, lines...), nil
+}
+
+// tailLines returns the last limit non-empty lines of name, or all of them
+// when limit is zero or less, reading the file backwards in chunks so a long
+// file costs no more than the tail asked for. A missing file yields nil.
+func tailLines(name string, limit int) ([][]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const chunk = 64 * 1024
+	var (
+		lines   [][]byte
+		partial []byte
+	)
+	for off := info.Size(); off > 0 && (limit <= 0 || len(lines) < limit); {
+		n := int64(chunk)
+		if off < n {
+			n = off
+		}
+		off -= n
+		buf := make([]byte, n, n+int64(len(partial)))
+		if _, err := f.ReadAt(buf, off); err != nil {
+			return nil, err
+		}
+		buf = append(buf, partial...)
+
+		// Everything before the first newline may continue in the previous
+		// chunk, so it is carried over unless this is the start of the file.
+		for {
+			i := bytes.LastIndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			if line := buf[i+1:]; len(line) > 0 {
+				lines = append(lines, line)
+			}
+			buf = buf[:i]
+		}
+		partial = buf
+	}
+	if len(partial) > 0 && (limit <= 0 || len(lines) < limit) {
+		lines = append(lines, partial)
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[:limit]
+	}
+
+	// lines was collected newest first.
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines, nil
+}
+
+// LoadDetectorState reads data/state.json. A missing file yields nil.
+func LoadDetectorState() (*types.DetectorState, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, "state.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var state types.DetectorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// SaveDetectorState writes data/state.json through a temporary file, so a
+// crash mid-write leaves the previous checkpoint intact.
+func SaveDetectorState(state *types.DetectorState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic("state.json", data)
+}
+
+// LoadHeldDigests reads data/digests.json. A missing file yields nil.
+func LoadHeldDigests() ([]types.HeldDigest, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, "digests.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var held []types.HeldDigest
+	if err := json.Unmarshal(data, &held); err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+// SaveHeldDigests writes data/digests.json, or removes it when held is empty.
+func SaveHeldDigests(held []types.HeldDigest) error {
+	if len(held) == 0 {
+		err := os.Remove(filepath.Join(dataDir, "digests.json"))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.Marshal(held)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic("digests.json", data)
+}
+
+// writeFileAtomic writes name in the data directory through a temporary
+// file, so readers never see it half written.
+func writeFileAtomic(name string, data []byte) error {
+	if err := ensureDataDir(); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dataDir, "."+strings.TrimSuffix(name, ".json")+"-*.json")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(dataDir, name))
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// LoadNotifyConfig reads data/notify.json. A missing file yields nil so
+// callers fall back to sending every detection to every configured sink but
+// the incident sinks.
+func LoadNotifyConfig() (*types.NotifyConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, "notify.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cfg types.NotifyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
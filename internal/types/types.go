@@ -1,8 +1,15 @@
 package types
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 type MarketEvent struct {
-	Slug  string `json:"slug"`
-	Title string `json:"title"`
+	Slug   string  `json:"slug"`
+	Title  string  `json:"title"`
+	Volume float64 `json:"volume"`
 }
 
 type Market struct {
@@ -35,6 +42,16 @@ func (m *Market) EventTitle() string {
 	return m.Question
 }
 
+// EventVolume is the volume of the market's event, or of the market itself
+// when the event's is not known.
+func (m *Market) EventVolume() float64 {
+	if len(m.Events) > 0 && m.Events[0].Volume > 0 {
+		return m.Events[0].Volume
+	}
+	vol, _ := strconv.ParseFloat(m.Volume, 64)
+	return vol
+}
+
 type Event struct {
 	ID        string   `json:"id"`
 	Slug      string   `json:"slug"`
@@ -49,7 +66,7 @@ type Event struct {
 }
 
 type SearchResponse struct {
-	Events  []Event `json:"events"`
+	Events  []Event  `json:"events"`
 	Markets []Market `json:"markets"`
 }
 
@@ -111,6 +128,23 @@ type SavedMarket struct {
 	AddedAt string `json:"addedAt"`
 }
 
+// MarketStats is the live trading activity on a watched market since the
+// tracker started.
+type MarketStats struct {
+	ConditionID string    `json:"conditionId"`
+	Slug        string    `json:"slug"`
+	EventSlug   string    `json:"eventSlug"`
+	Question    string    `json:"question"`
+	Trades      int       `json:"trades"`
+	VolumeUSD   float64   `json:"volumeUsd"`
+	BuyUSD      float64   `json:"buyUsd"`
+	SellUSD     float64   `json:"sellUsd"`
+	Detections  int       `json:"detections"`
+	LastPrice   float64   `json:"lastPrice"`
+	LastOutcome string    `json:"lastOutcome,omitempty"`
+	LastTradeAt time.Time `json:"lastTradeAt"`
+}
+
 type WsMessage struct {
 	EventType string `json:"event_type"`
 	Market    string `json:"market"`
@@ -119,19 +153,205 @@ type WsMessage struct {
 	Size      string `json:"size"`
 	Side      string `json:"side"`
 	Timestamp string `json:"timestamp"`
+	// PriceChanges lists the assets touched by a price_change event,
+	// which carries no top-level asset ID.
+	PriceChanges []WsPriceChange `json:"price_changes,omitempty"`
+}
+
+type WsPriceChange struct {
+	AssetID string `json:"asset_id"`
+}
+
+// MarketTrade is a trade on a watched market as seen on the WebSocket feed,
+// whether or not it met any detection criteria.
+type MarketTrade struct {
+	Market     *Market
+	AssetID    string
+	Side       string
+	Price      float64
+	Size       float64
+	UsdValue   float64
+	Timestamp  string
+	ReceivedAt time.Time
+}
+
+// Reason types recorded in DetectedTrade.ReasonTypes, one per criterion met.
+const (
+	ReasonLarge     = "large"
+	ReasonLiquidity = "liquidity"
+	ReasonEarly     = "early"
+	ReasonWhale     = "whale"
+
+	// ReasonTest marks a synthetic detection that met no criterion.
+	ReasonTest = "test"
+)
+
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank orders severities so they can be compared; unknown values rank
+// lowest.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
 }
 
 type DetectedTrade struct {
-	Market    *Market
-	AssetID   string
-	Side      string
-	Price     float64
-	Size      float64
-	UsdValue  float64
-	Timestamp string
-	Reason    string
-	Wallet    string
-	Trader    string
+	Market      *Market
+	AssetID     string
+	Side        string
+	Price       float64
+	Size        float64
+	UsdValue    float64
+	Timestamp   string
+	Reason      string
+	Reasons     []string
+	ReasonTypes []string
+	Severity    string
+	Wallet      string
+	Trader      string
+	WhaleName   string
+	Muted       bool
+	DetectedAt  time.Time
+}
+
+const (
+	MuteMarket = "market"
+	MuteWallet = "wallet"
+)
+
+// Mute silences notifications for a market (event or market slug) or wallet
+// until Until, or indefinitely when Until is empty.
+type Mute struct {
+	Kind    string `json:"kind"`
+	Target  string `json:"target"`
+	Until   string `json:"until,omitempty"`
+	AddedAt string `json:"addedAt"`
+}
+
+// Expired reports whether the mute has lapsed at now.
+func (m Mute) Expired(now time.Time) bool {
+	if m.Until == "" {
+		return false
+	}
+	until, err := time.Parse(time.RFC3339, m.Until)
+	return err == nil && !now.Before(until)
+}
+
+// Covers reports whether the mute applies to the detection's market, by
+// event or market slug, or to its wallet. It does not check expiry.
+func (m Mute) Covers(d DetectedTrade) bool {
+	switch m.Kind {
+	case MuteMarket:
+		return d.Market != nil && (strings.EqualFold(m.Target, d.Market.EventSlug()) || strings.EqualFold(m.Target, d.Market.Slug))
+	case MuteWallet:
+		return d.Wallet != "" && strings.EqualFold(m.Target, d.Wallet)
+	}
+	return false
+}
+
+// DetectorState is the contents of data/state.json: the detector's dedupe
+// set, per-market statistics and order book liquidity, checkpointed on
+// shutdown so a restart picks up where the last run left off.
+type DetectorState struct {
+	SavedAt   time.Time           `json:"savedAt"`
+	Seen      []SeenTrade         `json:"seen"`
+	Stats     []MarketStats       `json:"stats"`
+	Liquidity []LiquiditySnapshot `json:"liquidity"`
+}
+
+// HeldDigest is a digest batch quiet hours were holding back when the tracker
+// stopped, kept in data/digests.json until the next start delivers it.
+type HeldDigest struct {
+	Sink       string          `json:"sink"`
+	Start      time.Time       `json:"start"`
+	Detections []DetectedTrade `json:"detections"`
+}
+
+// SeenTrade is a transaction hash the detector has already handled, kept
+// until ExpiresAt.
+type SeenTrade struct {
+	Hash      string    `json:"hash"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LiquiditySnapshot is the order book depth in USD for an asset at FetchedAt.
+type LiquiditySnapshot struct {
+	AssetID   string    `json:"assetId"`
+	USD       float64   `json:"usd"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// NotifyConfig is the contents of data/notify.json: named sinks in addition
+// to those configured through the environment, and the rules routing each
+// detection to them.
+type NotifyConfig struct {
+	Sinks   []SinkConfig `json:"sinks"`
+	Routes  []Route      `json:"routes"`
+	Default []string     `json:"default"`
+}
+
+type SinkConfig struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	URL          string `json:"url,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	Secret       string `json:"secret,omitempty"`
+	Format       string `json:"format,omitempty"`
+	Template     string `json:"template,omitempty"`
+	QuietHours   string `json:"quietHours,omitempty"`
+	Timezone     string `json:"timezone,omitempty"`
+	Digest       string `json:"digest,omitempty"`
+	RoutingKey   string `json:"routingKey,omitempty"`
+	APIKey       string `json:"apiKey,omitempty"`
+	ResolveAfter string `json:"resolveAfter,omitempty"`
+	MinSeverity  string `json:"minSeverity,omitempty"`
+	Prefix       string `json:"prefix,omitempty"`
+	Trades       bool   `json:"trades,omitempty"`
+	Command      string `json:"command,omitempty"`
+	Timeout      string `json:"timeout,omitempty"`
+	Concurrency  int    `json:"concurrency,omitempty"`
+}
+
+// Route sends detections matching every set field of Match to Sinks. Routes
+// are evaluated in order and the first match wins unless Continue is set.
+type Route struct {
+	Name     string     `json:"name,omitempty"`
+	Match    RouteMatch `json:"match"`
+	Sinks    []string   `json:"sinks"`
+	Continue bool       `json:"continue,omitempty"`
+}
+
+type RouteMatch struct {
+	EventSlugs   []string `json:"eventSlugs,omitempty"`
+	ConditionIDs []string `json:"conditionIds,omitempty"`
+	Whales       []string `json:"whales,omitempty"`
+	Reasons      []string `json:"reasons,omitempty"`
+	Sides        []string `json:"sides,omitempty"`
+	MinSeverity  string   `json:"minSeverity,omitempty"`
+	MinUSD       float64  `json:"minUsd,omitempty"`
+	MaxUSD       float64  `json:"maxUsd,omitempty"`
+	MinPrice     float64  `json:"minPrice,omitempty"`
+	MaxPrice     float64  `json:"maxPrice,omitempty"`
+}
+
+type PricePoint struct {
+	T int64   `json:"t"`
+	P float64 `json:"p"`
+}
+
+type PriceHistory struct {
+	History []PricePoint `json:"history"`
 }
 
 type OrderBook struct {
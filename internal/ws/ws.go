@@ -1,34 +1,60 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
+	"github.com/mikefdy/polymarket-tool/internal/metrics"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
 	"github.com/mikefdy/polymarket-tool/internal/types"
 )
 
-type TradeHandler func(msg types.WsMessage)
+// TradeHandler receives each trade with a context carrying the frame's span.
+type TradeHandler func(ctx context.Context, msg types.WsMessage)
 
 type Client struct {
-	cfg             *config.Config
-	conn            *websocket.Conn
-	assetIDs        map[string]bool
-	mu              sync.RWMutex
-	onTrade         TradeHandler
-	done            chan struct{}
-	reconnectCount  int
-	maxReconnects   int
+	cfg            *config.Config
+	conn           *websocket.Conn
+	assetIDs       map[string]bool
+	mu             sync.RWMutex
+	writeMu        sync.Mutex
+	onTrade        TradeHandler
+	done           chan struct{}
+	reconnectCount int
+	maxReconnects  int
+	connected      bool
+	reconnects     int
+	messages       int64
+	lastMessageAt  time.Time
+	lastByAsset    map[string]time.Time
+	gaveUp         bool
+}
+
+// Status is a snapshot of the connection for monitoring.
+type Status struct {
+	Connected        bool      `json:"connected"`
+	Reconnects       int       `json:"reconnects"`
+	SubscribedAssets int       `json:"subscribedAssets"`
+	Messages         int64     `json:"messages"`
+	LastMessageAt    time.Time `json:"lastMessageAt"`
+	// GaveUp is set once reconnect attempts are exhausted; the client
+	// stays disconnected until restarted.
+	GaveUp bool `json:"gaveUp"`
 }
 
 func New(cfg *config.Config, onTrade TradeHandler) *Client {
 	return &Client{
 		cfg:           cfg,
 		assetIDs:      make(map[string]bool),
+		lastByAsset:   make(map[string]time.Time),
 		onTrade:       onTrade,
 		done:          make(chan struct{}),
 		maxReconnects: 10,
@@ -41,9 +67,14 @@ func (c *Client) Connect() error {
 		return err
 	}
 
+	c.mu.Lock()
 	c.conn = conn
+	c.connected = true
+	c.gaveUp = false
+	c.mu.Unlock()
+	metrics.WSConnected.Set(1)
 	c.reconnectCount = 0
-	log.Println("[WS] Connected")
+	logging.For("ws").Info("connected", "url", c.cfg.ClobWsURL)
 
 	c.subscribeAll()
 
@@ -53,6 +84,10 @@ func (c *Client) Connect() error {
 
 func (c *Client) readLoop() {
 	defer func() {
+		c.mu.Lock()
+		c.connected = false
+		c.mu.Unlock()
+		metrics.WSConnected.Set(0)
 		c.conn.Close()
 		c.scheduleReconnect()
 	}()
@@ -66,26 +101,68 @@ func (c *Client) readLoop() {
 
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			log.Printf("[WS] Read error: %v", err)
+			logging.For("ws").Warn("read failed", "err", err)
 			return
 		}
 
-		var msgs []types.WsMessage
-		if err := json.Unmarshal(message, &msgs); err != nil {
-			continue
+		c.handleFrame(message)
+	}
+}
+
+// handleFrame dispatches the trades in one market channel frame. Its span
+// is the root of each trade's trace.
+func (c *Client) handleFrame(message []byte) {
+	ctx, span := tracing.Tracer("ws").Start(context.Background(), "ws.frame",
+		trace.WithAttributes(attribute.Int("ws.frame.bytes", len(message))))
+	defer span.End()
+
+	now := time.Now()
+	c.mu.Lock()
+	c.messages++
+	c.lastMessageAt = now
+	c.mu.Unlock()
+
+	var msgs []types.WsMessage
+	if err := json.Unmarshal(message, &msgs); err != nil {
+		metrics.WSMessages.WithLabelValues("unparsed").Inc()
+		span.SetAttributes(attribute.Bool("ws.frame.unparsed", true))
+		return
+	}
+	span.SetAttributes(attribute.Int("ws.frame.messages", len(msgs)))
+	c.touchAssets(msgs, now)
+
+	for _, msg := range msgs {
+		metrics.WSMessages.WithLabelValues(msg.EventType).Inc()
+		if msg.EventType == "last_trade_price" {
+			c.onTrade(ctx, msg)
 		}
+	}
+}
+
+// touchAssets records when each subscribed asset last appeared in a frame.
+func (c *Client) touchAssets(msgs []types.WsMessage, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		for _, msg := range msgs {
-			if msg.EventType == "last_trade_price" {
-				c.onTrade(msg)
-			}
+	touch := func(id string) {
+		if c.assetIDs[id] {
+			c.lastByAsset[id] = now
+		}
+	}
+	for _, msg := range msgs {
+		touch(msg.AssetID)
+		for _, pc := range msg.PriceChanges {
+			touch(pc.AssetID)
 		}
 	}
 }
 
 func (c *Client) scheduleReconnect() {
 	if c.reconnectCount >= c.maxReconnects {
-		log.Println("[WS] Max reconnect attempts reached")
+		c.mu.Lock()
+		c.gaveUp = true
+		c.mu.Unlock()
+		logging.For("ws").Error("giving up reconnecting", "attempts", c.reconnectCount)
 		return
 	}
 
@@ -95,7 +172,11 @@ func (c *Client) scheduleReconnect() {
 	}
 
 	c.reconnectCount++
-	log.Printf("[WS] Reconnecting in %v...", delay)
+	c.mu.Lock()
+	c.reconnects++
+	c.mu.Unlock()
+	metrics.WSReconnects.Inc()
+	logging.For("ws").Info("reconnecting", "in", delay, "attempt", c.reconnectCount)
 
 	time.Sleep(delay)
 
@@ -104,7 +185,7 @@ func (c *Client) scheduleReconnect() {
 		return
 	default:
 		if err := c.Connect(); err != nil {
-			log.Printf("[WS] Reconnect failed: %v", err)
+			logging.For("ws").Warn("reconnect failed", "err", err)
 			c.scheduleReconnect()
 		}
 	}
@@ -115,13 +196,74 @@ func (c *Client) Subscribe(assetIDs []string) {
 	for _, id := range assetIDs {
 		c.assetIDs[id] = true
 	}
+	metrics.WSSubscribedAssets.Set(float64(len(c.assetIDs)))
+	connected := c.connected
 	c.mu.Unlock()
 
-	if c.conn != nil {
+	if connected {
 		c.sendSubscription(assetIDs)
 	}
 }
 
+// Unsubscribe stops receiving trades for assetIDs.
+func (c *Client) Unsubscribe(assetIDs []string) {
+	if len(assetIDs) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	for _, id := range assetIDs {
+		delete(c.assetIDs, id)
+		delete(c.lastByAsset, id)
+	}
+	metrics.WSSubscribedAssets.Set(float64(len(c.assetIDs)))
+	connected := c.connected
+	c.mu.Unlock()
+
+	if !connected {
+		return
+	}
+
+	msg := map[string]interface{}{
+		"assets_ids": assetIDs,
+		"operation":  "unsubscribe",
+	}
+	if err := c.writeJSON(msg); err != nil {
+		logging.For("ws").Error("unsubscribe failed", "err", err)
+		return
+	}
+
+	logging.For("ws").Info("unsubscribed", "assets", len(assetIDs))
+}
+
+// Status reports the connection state and subscription count.
+func (c *Client) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Status{
+		Connected:        c.connected,
+		Reconnects:       c.reconnects,
+		SubscribedAssets: len(c.assetIDs),
+		Messages:         c.messages,
+		LastMessageAt:    c.lastMessageAt,
+		GaveUp:           c.gaveUp,
+	}
+}
+
+// LastMessages returns when each subscribed asset last appeared on the feed.
+// Assets not seen since subscribing have a zero time.
+func (c *Client) LastMessages() map[string]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	last := make(map[string]time.Time, len(c.assetIDs))
+	for id := range c.assetIDs {
+		last[id] = c.lastByAsset[id]
+	}
+	return last
+}
+
 func (c *Client) subscribeAll() {
 	c.mu.RLock()
 	ids := make([]string, 0, len(c.assetIDs))
@@ -141,12 +283,24 @@ func (c *Client) sendSubscription(assetIDs []string) {
 		"type":       "market",
 	}
 
-	if err := c.conn.WriteJSON(msg); err != nil {
-		log.Printf("[WS] Subscribe error: %v", err)
+	if err := c.writeJSON(msg); err != nil {
+		logging.For("ws").Error("subscribe failed", "err", err)
 		return
 	}
 
-	fmt.Printf("[WS] Subscribed to %d assets\n", len(assetIDs))
+	logging.For("ws").Info("subscribed", "assets", len(assetIDs))
+}
+
+// writeJSON serializes writes, which gorilla/websocket does not allow to run
+// concurrently.
+func (c *Client) writeJSON(v interface{}) error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteJSON(v)
 }
 
 func (c *Client) Close() {
@@ -1,122 +1,238 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
 	"github.com/mikefdy/polymarket-tool/internal/api"
 	"github.com/mikefdy/polymarket-tool/internal/config"
+	"github.com/mikefdy/polymarket-tool/internal/dashboard"
 	"github.com/mikefdy/polymarket-tool/internal/detector"
+	"github.com/mikefdy/polymarket-tool/internal/doctor"
+	"github.com/mikefdy/polymarket-tool/internal/health"
+	"github.com/mikefdy/polymarket-tool/internal/logging"
 	"github.com/mikefdy/polymarket-tool/internal/notifier"
+	"github.com/mikefdy/polymarket-tool/internal/pipeline"
+	"github.com/mikefdy/polymarket-tool/internal/report"
+	"github.com/mikefdy/polymarket-tool/internal/server"
 	"github.com/mikefdy/polymarket-tool/internal/storage"
+	"github.com/mikefdy/polymarket-tool/internal/tracing"
 	"github.com/mikefdy/polymarket-tool/internal/types"
 	"github.com/mikefdy/polymarket-tool/internal/ws"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		printUsage()
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	cmd := os.Args[1]
-	args := os.Args[2:]
-
-	switch cmd {
-	case "start":
-		cmdStart()
-	case "add-market":
-		cmdAddMarket(args)
-	case "markets":
-		cmdMarkets(args)
-	case "fat-trades":
-		cmdFatTrades(args)
-	case "discover-whales":
-		cmdDiscoverWhales(args)
-	case "whale-trades":
-		cmdWhaleTrades(args)
-	case "list":
-		cmdList(args)
-	case "help", "-h", "--help":
-		printUsage()
-	default:
-		fmt.Printf("Unknown command: %s\n\n", cmd)
-		printUsage()
-		os.Exit(1)
+// ============= START COMMAND =============
+
+func newStartCmd() *cobra.Command {
+	var httpAddr, logLevel, logFormat string
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the real-time WebSocket tracker (optional dashboard/API)",
+		Long: `Start the real-time tracker: watch saved markets and SEARCH_QUERIES results
+over the WebSocket, detect fat trades and notify every configured sink.
+
+On SIGINT or SIGTERM queued work is drained within SHUTDOWN_GRACE and the
+detector state is checkpointed to the data directory.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdStart(httpAddr, logLevel, logFormat)
+		},
 	}
+	cmd.Flags().StringVar(&httpAddr, "http", "", "serve the dashboard and JSON API on this address, e.g. 127.0.0.1:8080")
+	cmd.Flags().StringVar(&logLevel, "log-level", "", "log level: debug, info, warn, error (default: LOG_LEVEL or info)")
+	cmd.Flags().StringVar(&logFormat, "log-format", "", "log format: text, json (default: LOG_FORMAT or text)")
+	cmd.RegisterFlagCompletionFunc("log-level", fixedValues("debug", "info", "warn", "error"))
+	cmd.RegisterFlagCompletionFunc("log-format", fixedValues("text", "json"))
+	return cmd
 }
 
-func printUsage() {
-	fmt.Println(`Polymarket Tool - Real-time detection of large trades on Polymarket
+func cmdStart(httpAddr, logLevel, logFormat string) error {
+	cfg := config.Load()
+	if logLevel == "" {
+		logLevel = cfg.LogLevel
+	}
+	if logFormat == "" {
+		logFormat = cfg.LogFormat
+	}
+	if err := logging.Setup(os.Stderr, logLevel, logFormat); err != nil {
+		return err
+	}
 
-Usage:
-  polymarket-tool <command> [arguments]
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TraceExporter, cfg.TraceEndpoint, cfg.TraceSampleRatio)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("flushing traces failed", "err", err)
+		}
+	}()
 
-Commands:
-  start                   Start real-time WebSocket tracker
-  markets [query]         Search and add markets interactively
-  add-market <url>        Add a market by URL or slug
-  fat-trades [min-usd]    Scan historical trades for saved markets
-  discover-whales [sel]   Add whales from leaderboard (top10, all, 1,2,3)
-  whale-trades [name]     View recent trades for tracked whales
-  list whales             List tracked whales
-  list markets            List saved markets
-  list clear-whales       Remove all tracked whales
-  list clear-markets      Remove all saved markets
-  list remove-whale <addr>    Remove a specific whale
-  list remove-market <slug>   Remove a specific market
-  help                    Show this help
+	// Diagnostics go to stderr; stdout is left to the console sink so
+	// detections can be piped on their own.
+	slog.Info("starting polymarket-tool",
+		"minTradeUsd", cfg.MinTradeUSD,
+		"minLiquidityRatio", cfg.MinLiquidityRatio,
+		"webhook", cfg.WebhookURL != "",
+		"jsonWebhook", cfg.JSONWebhookURL != "",
+		"queries", cfg.SearchQueries,
+		"workers", cfg.PipelineWorkers,
+		"tracing", cfg.TraceExporter)
 
-Environment Variables:
-  MIN_TRADE_USD           Minimum trade value (default: 1000)
-  MIN_LIQUIDITY_RATIO     Min trade as % of orderbook (default: 0.05)
-  WEBHOOK_URL             Discord/Slack webhook for notifications
-  SEARCH_QUERIES          Comma-separated market search terms
+	storage.SetDetectionsMaxSize(int64(cfg.DetectionsMaxMB) << 20)
 
-Examples:
-  polymarket-tool markets fed                     # Search and add markets
-  polymarket-tool add-market fed-decision         # Add by slug
-  polymarket-tool discover-whales top10           # Track top 10 traders
-  polymarket-tool fat-trades 500                  # Find trades > $500
-  polymarket-tool start                           # Start real-time tracking
-  MIN_TRADE_USD=100 polymarket-tool start         # Custom threshold`)
-}
+	whales, _ := storage.LoadWhales()
+	savedMarkets, _ := storage.LoadMarkets()
+	slog.Info("loaded watch lists", "whales", len(whales), "savedMarkets", len(savedMarkets))
 
-// ============= START COMMAND =============
+	notifyCfg, err := storage.LoadNotifyConfig()
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", filepath.Join(storage.Dir(), "notify.json"), err)
+	}
 
-func cmdStart() {
-	cfg := config.Load()
+	apiClient := api.New(cfg)
+	notify, err := notifier.New(cfg, notifyCfg)
+	if err != nil {
+		return err
+	}
+	slog.Info("notifier ready", "sinks", notify.Sinks())
+
+	if held, err := storage.LoadHeldDigests(); err != nil {
+		slog.Error("failed to load held digests", "err", err)
+	} else if len(held) > 0 {
+		notify.RestoreDigests(held)
+		if err := storage.SaveHeldDigests(nil); err != nil {
+			slog.Error("failed to clear held digests", "err", err)
+		}
+	}
 
-	fmt.Println("Polymarket Tool")
-	fmt.Println("===============")
-	fmt.Printf("Min trade: $%.0f\n", cfg.MinTradeUSD)
-	fmt.Printf("Min liquidity ratio: %.0f%%\n", cfg.MinLiquidityRatio*100)
-	fmt.Printf("Webhook: %s\n", boolStr(cfg.WebhookURL != "", "configured", "none"))
-	fmt.Printf("Queries: %s\n", strings.Join(cfg.SearchQueries, ", "))
+	mutes, err := storage.LoadMutes()
+	if err != nil {
+		slog.Error("failed to load mutes", "err", err)
+	}
+	notify.SetMutes(mutes)
+	go watchMutes(notify)
 
-	whales, _ := storage.LoadWhales()
-	savedMarkets, _ := storage.LoadMarkets()
-	fmt.Printf("Tracking %d whales\n", len(whales))
-	fmt.Printf("Saved markets: %d\n", len(savedMarkets))
-	fmt.Println()
+	// ctx ends when shutdown starts; reports is waited on before the
+	// notifier closes so no report goes out after it.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var reports sync.WaitGroup
 
-	apiClient := api.New(cfg)
-	notify := notifier.New(cfg)
-	detect := detector.New(cfg, apiClient, notify.Notify)
+	for period, spec := range map[string]string{report.Daily: cfg.ReportDaily, report.Weekly: cfg.ReportWeekly} {
+		if spec == "" {
+			continue
+		}
+		schedule, err := report.ParseSchedule(period, spec)
+		if err != nil {
+			return err
+		}
+		logging.For("report").Info("report scheduled", "period", period, "next", schedule.Next(time.Now()))
+		reports.Add(1)
+		go func() {
+			defer reports.Done()
+			runReportSchedule(ctx, schedule, cfg, apiClient, notify)
+		}()
+	}
+
+	var apiServer *server.Server
+
+	onDetection := func(ctx context.Context, d types.DetectedTrade) {
+		// Muted detections are still recorded, just not delivered.
+		d.Muted = notify.Muted(d)
+		payload := notifier.NewPayload(d)
+		if err := storage.AppendDetection(payload); err != nil {
+			slog.Error("failed to record detection", "err", err)
+		}
+		if apiServer != nil {
+			apiServer.RecordDetection(payload)
+		}
+		notify.Notify(ctx, d)
+	}
+
+	// The WebSocket reader only queues trades; detection runs on the
+	// pipeline's workers and delivery on its notification stage.
+	var pipe *pipeline.Pipeline
+	detect := detector.New(cfg, apiClient, func(ctx context.Context, d types.DetectedTrade) {
+		pipe.Detected(ctx, d)
+	})
+	detect.SetTradeHandler(func(t types.MarketTrade) {
+		notify.PublishTrade(t)
+		if apiServer != nil {
+			apiServer.RecordTrade(t)
+		}
+	})
 	detect.SetWhales(whales)
+	if state, err := storage.LoadDetectorState(); err != nil {
+		slog.Error("failed to load detector checkpoint, starting fresh", "err", err)
+	} else if state != nil {
+		detect.Restore(state)
+		slog.Info("restored detector checkpoint",
+			"savedAt", state.SavedAt,
+			"seen", len(state.Seen),
+			"markets", len(state.Stats),
+			"liquidity", len(state.Liquidity))
+	}
+
+	pipe, err = pipeline.New(pipeline.Options{
+		Workers:   cfg.PipelineWorkers,
+		QueueSize: cfg.PipelineQueueSize,
+		Overflow:  cfg.PipelineOverflow,
+	}, detect.ProcessWsTrade, onDetection)
+	if err != nil {
+		return err
+	}
+
+	wsClient := ws.New(cfg, pipe.Submit)
 
-	wsClient := ws.New(cfg, detect.ProcessWsTrade)
+	monitor := health.New(health.Options{
+		StaleAfter: cfg.HealthStaleAfter,
+		Alerts:     cfg.HealthAlerts,
+		Sinks:      cfg.HealthSinks,
+	}, wsClient, apiClient, notify)
+
+	if httpAddr != "" {
+		apiServer = server.New(apiClient, detect, wsClient, notify, monitor)
+		apiServer.SetToken(cfg.HTTPToken)
+		go func() {
+			if err := apiServer.ListenAndServe(httpAddr); err != nil {
+				fatal("HTTP server failed", "err", err)
+			}
+		}()
+	}
 
 	refresh := func() {
+		// Reload so markets added or removed through the API are picked up.
+		if saved, err := storage.LoadMarkets(); err == nil {
+			savedMarkets = saved
+		}
 		markets := discoverMarkets(cfg, apiClient, savedMarkets)
 		assetIDs := detect.AddMarkets(markets)
 		wsClient.Subscribe(assetIDs)
@@ -125,9 +241,11 @@ func cmdStart() {
 	refresh()
 
 	if err := wsClient.Connect(); err != nil {
-		log.Fatalf("WebSocket connection failed: %v", err)
+		return fmt.Errorf("WebSocket connection failed: %w", err)
 	}
 
+	go monitor.Run(ctx)
+
 	ticker := time.NewTicker(time.Duration(cfg.PollIntervalMs) * time.Millisecond)
 	defer ticker.Stop()
 
@@ -139,25 +257,137 @@ func cmdStart() {
 		case <-ticker.C:
 			refresh()
 		case <-sigCh:
-			fmt.Println("\nShutting down...")
-			wsClient.Close()
-			return
+			ticker.Stop()
+			cancel()
+			reports.Wait()
+			shutdown(cfg.ShutdownGrace, sigCh, wsClient, apiServer, pipe, notify, detect)
+			return nil
+		}
+	}
+}
+
+// minFlushTime is how long the notifier gets to send cooldown summaries and
+// digests when draining the pipeline used up the grace period.
+const minFlushTime = 5 * time.Second
+
+// shutdown stops taking in trades, drains the pipeline and the notifier
+// within grace, then checkpoints the detector. The notifier is only closed
+// once the pipeline has stopped delivering to it, and gets at least
+// minFlushTime. A second signal cuts the drain and the flush short; the
+// checkpoint is still written.
+func shutdown(grace time.Duration, sigCh <-chan os.Signal, wsClient *ws.Client, apiServer *server.Server,
+	pipe *pipeline.Pipeline, notify *notifier.Notifier, detect *detector.Detector) {
+	start := time.Now()
+	slog.Info("shutting down", "grace", grace)
+
+	abandon, stop := context.WithCancel(context.Background())
+	defer stop()
+	ctx, cancel := context.WithTimeout(abandon, grace)
+	defer cancel()
+	go func() {
+		select {
+		case <-sigCh:
+			slog.Warn("second signal, abandoning the drain")
+			stop()
+		case <-abandon.Done():
+		}
+	}()
+
+	wsClient.Close()
+	if apiServer != nil {
+		if err := apiServer.Shutdown(ctx); err != nil {
+			slog.Warn("HTTP server did not stop cleanly", "err", err)
 		}
 	}
+
+	if err := pipe.Shutdown(ctx); err != nil {
+		slog.Warn("pipeline not drained", "err", err)
+	}
+	flushCtx, cancelFlush := context.WithTimeout(abandon, max(time.Until(start.Add(grace)), minFlushTime))
+	defer cancelFlush()
+	if err := notify.Close(flushCtx); err != nil {
+		slog.Warn("notifier not flushed", "err", err)
+	}
+	saveHeldDigests(notify)
+
+	state := detect.Checkpoint()
+	if err := storage.SaveDetectorState(state); err != nil {
+		slog.Error("failed to checkpoint detector", "err", err)
+	} else {
+		slog.Info("checkpointed detector", "seen", len(state.Seen), "markets", len(state.Stats), "liquidity", len(state.Liquidity))
+	}
+
+	slog.Info("shutdown complete", "took", time.Since(start).Round(time.Millisecond))
+}
+
+// closeNotifier closes a notifier built for a one-off command, allowing it
+// minFlushTime.
+func closeNotifier(notify *notifier.Notifier) {
+	ctx, cancel := context.WithTimeout(context.Background(), minFlushTime)
+	defer cancel()
+	if err := notify.Close(ctx); err != nil {
+		slog.Warn("notifier not flushed", "err", err)
+	}
+	saveHeldDigests(notify)
+}
+
+// saveHeldDigests keeps the digest batches quiet hours held back at close
+// for the next start to deliver.
+func saveHeldDigests(notify *notifier.Notifier) {
+	held := notify.HeldDigests()
+	if len(held) == 0 {
+		return
+	}
+	count := 0
+	for _, h := range held {
+		count += len(h.Detections)
+	}
+
+	// Add to any a previous run left that no start has picked up yet.
+	previous, err := storage.LoadHeldDigests()
+	if err == nil {
+		err = storage.SaveHeldDigests(append(previous, held...))
+	}
+	if err != nil {
+		slog.Error("failed to save held digests, dropping them", "detections", count, "err", err)
+		return
+	}
+	slog.Info("saved digests held by quiet hours for the next start", "detections", count)
+}
+
+// watchMutes reloads data/mutes.json so mute commands take effect in a running
+// tracker without a restart.
+func watchMutes(notify *notifier.Notifier) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		mutes, err := storage.LoadMutes()
+		if err != nil {
+			slog.Error("failed to reload mutes", "err", err)
+			continue
+		}
+		notify.SetMutes(mutes)
+	}
 }
 
 func discoverMarkets(cfg *config.Config, apiClient *api.Client, savedMarkets []types.SavedMarket) []types.Market {
+	logger := logging.For("discovery")
 	markets := make(map[string]types.Market)
 
 	if len(savedMarkets) > 0 {
-		fmt.Printf("[Discovery] Loading %d saved markets...\n", len(savedMarkets))
+		logger.Debug("loading saved markets", "count", len(savedMarkets))
 		for _, sm := range savedMarkets {
 			event, err := apiClient.GetEventBySlug(sm.Slug)
 			if err != nil {
+				logger.Warn("saved market lookup failed", "slug", sm.Slug, "err", err)
 				continue
 			}
 			for _, m := range event.Markets {
 				if m.ConditionID != "" && m.ClobTokens != "" {
+					if len(m.Events) == 0 {
+						m.Events = []types.MarketEvent{{Slug: event.Slug, Title: event.Title}}
+					}
 					markets[m.ConditionID] = m
 				}
 			}
@@ -165,10 +395,10 @@ func discoverMarkets(cfg *config.Config, apiClient *api.Client, savedMarkets []t
 	}
 
 	for _, query := range cfg.SearchQueries {
-		fmt.Printf("[Discovery] Searching: %s\n", query)
+		logger.Debug("searching", "query", query)
 		results, err := apiClient.SearchMarkets(query)
 		if err != nil {
-			fmt.Printf("[Discovery] Search failed: %s\n", query)
+			logger.Warn("search failed", "query", query, "err", err)
 			continue
 		}
 		for _, m := range results {
@@ -178,7 +408,7 @@ func discoverMarkets(cfg *config.Config, apiClient *api.Client, savedMarkets []t
 		}
 	}
 
-	fmt.Printf("[Discovery] Found %d unique markets\n", len(markets))
+	logger.Info("discovered markets", "count", len(markets))
 
 	result := make([]types.Market, 0, len(markets))
 	for _, m := range markets {
@@ -187,28 +417,197 @@ func discoverMarkets(cfg *config.Config, apiClient *api.Client, savedMarkets []t
 	return result
 }
 
-// ============= FAT-TRADES COMMAND =============
+// ============= DASHBOARD COMMAND =============
+
+func newDashboardCmd() *cobra.Command {
+	var logFile string
+	cmd := &cobra.Command{
+		Use:   "dashboard",
+		Short: "Watch detections, markets and whales in a terminal UI",
+		Long: `Full-screen terminal dashboard over the same WebSocket feed and detector as
+start: a scrolling feed of detections, the watched markets with their last
+price and change over the past hour, WebSocket status and message rate, and
+tracked whales' recent trades.
+
+The dashboard only displays. Detections are not sent to sinks or recorded, so
+keep start running for alerts. Mutes and whales added here are saved to the
+data directory; a running start picks up mutes within seconds and whales on
+restart.
+
+Keys: ↑/↓ or j/k select, Tab switches between the feed and the whale panel,
+Enter opens details, m mutes the market, w adds the trader as a whale, and
+q quits.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdDashboard(logFile)
+		},
+	}
+	cmd.Flags().StringVar(&logFile, "log-file", "", "append logs to this file (default: dashboard.log in the data directory)")
+	cmd.MarkFlagFilename("log-file")
+	return cmd
+}
 
-func cmdFatTrades(args []string) {
+func cmdDashboard(logFile string) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("dashboard needs a terminal; use start for a plain feed")
+	}
 	cfg := config.Load()
 
-	minUSD := cfg.MinTradeUSD
-	if len(args) > 0 {
-		if v, err := strconv.ParseFloat(args[0], 64); err == nil {
-			minUSD = v
+	// The screen belongs to the dashboard, so logs go to a file.
+	if logFile == "" {
+		logFile = filepath.Join(storage.Dir(), "dashboard.log")
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := logging.Setup(f, cfg.LogLevel, cfg.LogFormat); err != nil {
+		return err
+	}
+
+	whales, _ := storage.LoadWhales()
+	savedMarkets, _ := storage.LoadMarkets()
+	apiClient := api.New(cfg)
+
+	var (
+		dash *dashboard.Dashboard
+		pipe *pipeline.Pipeline
+	)
+	detect := detector.New(cfg, apiClient, func(ctx context.Context, d types.DetectedTrade) {
+		pipe.Detected(ctx, d)
+	})
+	detect.SetTradeHandler(func(t types.MarketTrade) {
+		dash.RecordTrade(t)
+	})
+	detect.SetWhales(whales)
+	// Start's checkpoint gives the markets panel its stats; the dashboard
+	// never writes one, which would clobber start's.
+	if state, err := storage.LoadDetectorState(); err != nil {
+		slog.Warn("failed to load detector checkpoint", "err", err)
+	} else if state != nil {
+		detect.Restore(state)
+	}
+
+	pipe, err = pipeline.New(pipeline.Options{
+		Workers:   cfg.PipelineWorkers,
+		QueueSize: cfg.PipelineQueueSize,
+		Overflow:  cfg.PipelineOverflow,
+	}, detect.ProcessWsTrade, func(ctx context.Context, d types.DetectedTrade) {
+		dash.RecordDetection(d)
+	})
+	if err != nil {
+		return err
+	}
+
+	wsClient := ws.New(cfg, pipe.Submit)
+	dash = dashboard.New(apiClient, detect, wsClient)
+
+	if err := wsClient.Connect(); err != nil {
+		return fmt.Errorf("WebSocket connection failed: %w", err)
+	}
+	defer wsClient.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Markets are looked up in the background so the screen comes up at
+	// once and fills in.
+	go func() {
+		ticker := time.NewTicker(time.Duration(cfg.PollIntervalMs) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			if saved, err := storage.LoadMarkets(); err == nil {
+				savedMarkets = saved
+			}
+			markets := discoverMarkets(cfg, apiClient, savedMarkets)
+			wsClient.Subscribe(detect.AddMarkets(markets))
+			dash.Watch(markets)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
 		}
+	}()
+
+	err = dash.Run(ctx)
+	cancel()
+
+	// Nothing queued is delivered anywhere, so don't wait long for it.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), time.Second)
+	defer drainCancel()
+	pipe.Shutdown(drainCtx)
+	return err
+}
+
+// ============= FAT-TRADES COMMAND =============
+
+func newFatTradesCmd() *cobra.Command {
+	var opts fatTradesOptions
+	cmd := &cobra.Command{
+		Use:   "fat-trades [min-usd]",
+		Short: "Scan historical trades for saved markets",
+		Example: `  polymarket-tool fat-trades --min-usd 5000
+  polymarket-tool fat-trades --since 6h --limit 5000`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				if cmd.Flags().Changed("min-usd") {
+					return fmt.Errorf("give the minimum either as an argument or with --min-usd, not both")
+				}
+				v, err := strconv.ParseFloat(args[0], 64)
+				if err != nil || v < 0 {
+					return fmt.Errorf("invalid min-usd %q", args[0])
+				}
+				opts.minUSD = v
+			}
+			return cmdFatTrades(opts)
+		},
+	}
+	cmd.Flags().Float64Var(&opts.minUSD, "min-usd", 0, "minimum trade value in USD (default: MIN_TRADE_USD)")
+	cmd.Flags().IntVar(&opts.limit, "limit", 2000, "number of recent trades to scan")
+	cmd.Flags().Var((*dayDuration)(&opts.since), "since", "only trades newer than this, e.g. 6h or 2d")
+	addFormatFlag(cmd, &opts.format)
+	return cmd
+}
+
+type fatTradesOptions struct {
+	minUSD float64
+	limit  int
+	since  time.Duration
+	format outputFormat
+}
+
+func cmdFatTrades(opts fatTradesOptions) error {
+	cfg := config.Load()
+	out := opts.format.status()
+
+	minUSD := opts.minUSD
+	if minUSD == 0 {
+		minUSD = cfg.MinTradeUSD
+	}
+	if opts.limit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
 	}
 
 	savedMarkets, _ := storage.LoadMarkets()
 	if len(savedMarkets) == 0 {
-		fmt.Println("No markets saved. Run: polymarket-tool add-market <url>")
-		return
+		fmt.Fprintln(out, "No markets saved. Run: polymarket-tool add-market <url>")
+		if opts.format.machine() {
+			return opts.format.write([]fatTradeRow{})
+		}
+		return nil
 	}
 
-	fmt.Println("Fat Trades Scanner")
-	fmt.Println("==================")
-	fmt.Printf("Min trade value: $%.0f\n", minUSD)
-	fmt.Printf("Scanning %d saved markets...\n\n", len(savedMarkets))
+	fmt.Fprintln(out, "Fat Trades Scanner")
+	fmt.Fprintln(out, "==================")
+	fmt.Fprintf(out, "Min trade value: $%.0f\n", minUSD)
+	fmt.Fprintf(out, "Scanning %d saved markets...\n\n", len(savedMarkets))
 
 	apiClient := api.New(cfg)
 
@@ -227,20 +626,15 @@ func cmdFatTrades(args []string) {
 		}
 	}
 
-	fmt.Printf("Loaded %d market conditions\n", len(conditionToMarket))
-	fmt.Println("Fetching recent trades...\n")
+	fmt.Fprintf(out, "Loaded %d market conditions\n", len(conditionToMarket))
+	fmt.Fprint(out, "Fetching recent trades...\n\n")
 
-	trades, err := apiClient.GetRecentTrades(2000)
+	trades, err := apiClient.GetRecentTrades(opts.limit)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
+		return err
 	}
 
-	// Filter and display fat trades
-	fmt.Println("Fat Trades Found:")
-	fmt.Println(strings.Repeat("=", 90))
-
-	count := 0
+	rows := []fatTradeRow{}
 	for _, t := range trades {
 		market := conditionToMarket[t.ConditionID]
 		if market == nil {
@@ -251,72 +645,150 @@ func cmdFatTrades(args []string) {
 		if usdValue < minUSD {
 			continue
 		}
+		if opts.since > 0 && time.Since(time.Unix(t.Timestamp, 0)) > opts.since {
+			continue
+		}
+		rows = append(rows, fatTradeRow{
+			Trade:     t,
+			UsdValue:  usdValue,
+			Question:  market.Question,
+			EventSlug: market.EventSlug(),
+		})
+	}
+
+	if opts.format.machine() {
+		fmt.Fprintf(out, "Found %d fat trades (>$%.0f) in your markets\n", len(rows), minUSD)
+		return opts.format.write(rows)
+	}
+
+	// Display fat trades
+	fmt.Println("Fat Trades Found:")
+	fmt.Println(strings.Repeat("=", 90))
 
-		count++
-		trader := t.Name
+	for _, r := range rows {
+		trader := r.Name
 		if trader == "" {
-			trader = t.Pseudonym
+			trader = r.Pseudonym
 		}
-		if trader == "" && t.ProxyWallet != "" {
-			trader = t.ProxyWallet[:12] + "..."
+		if trader == "" && r.ProxyWallet != "" {
+			trader = r.ProxyWallet[:12] + "..."
 		}
 
-		timeStr := formatTimeAgo(t.Timestamp)
-		title := market.Question
+		timeStr := formatTimeAgo(r.Timestamp)
+		title := r.Question
 		if len(title) > 45 {
 			title = title[:45] + "..."
 		}
 
-		fmt.Printf("\n%s | %s | %s\n", timeStr, strings.ToUpper(t.Side), formatUSD(usdValue))
+		fmt.Printf("\n%s | %s | %s\n", timeStr, strings.ToUpper(r.Side), formatUSD(r.UsdValue))
 		fmt.Printf("  Market: %s\n", title)
 		fmt.Printf("  Trader: %s\n", trader)
-		fmt.Printf("  Wallet: %s\n", t.ProxyWallet)
+		fmt.Printf("  Wallet: %s\n", r.ProxyWallet)
 	}
 
 	fmt.Println()
 	fmt.Println(strings.Repeat("=", 90))
-	fmt.Printf("Found %d fat trades (>$%.0f) in your markets\n", count, minUSD)
+	fmt.Printf("Found %d fat trades (>$%.0f) in your markets\n", len(rows), minUSD)
+	return nil
+}
+
+// fatTradeRow is one fat-trades result in the machine-readable formats.
+type fatTradeRow struct {
+	types.Trade
+	UsdValue  float64 `json:"usdValue"`
+	Question  string  `json:"question"`
+	EventSlug string  `json:"eventSlug"`
 }
 
 // ============= MARKETS COMMAND =============
 
-func cmdMarkets(args []string) {
+func newMarketsCmd() *cobra.Command {
+	var opts marketsOptions
+	cmd := &cobra.Command{
+		Use:   "markets [query]",
+		Short: "Search and add markets interactively",
+		Long: `Search markets and pick events to save, by number at a prompt or with
+--select, --all or --yes. --min-volume and --ends-before narrow the results
+first; numbers refer to the narrowed list. Without a selection flag stdin must
+be a terminal. With --format json, ndjson or csv the results are printed
+instead of the table and nothing is asked.`,
+		Example: `  polymarket-tool markets fed
+  polymarket-tool markets trump tariffs
+  polymarket-tool markets fed --select 1,3
+  polymarket-tool markets election --min-volume 1e6 --ends-before 2026-12-31 --yes
+  polymarket-tool markets bitcoin --format csv > bitcoin.csv`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdMarkets(args, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.selection, "select", "", "add the events at these comma-separated numbers")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "add every listed event")
+	cmd.Flags().Float64Var(&opts.minVolume, "min-volume", 0, "only events with at least this volume in USD")
+	cmd.Flags().Var((*dateValue)(&opts.endsBefore), "ends-before", "only events ending before this date, e.g. 2026-12-31")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "don't ask: add the --select events, or every listed event")
+	cmd.MarkFlagsMutuallyExclusive("select", "all")
+	addFormatFlag(cmd, &opts.format)
+	return cmd
+}
+
+type marketsOptions struct {
+	selection  string
+	all        bool
+	minVolume  float64
+	endsBefore time.Time
+	yes        bool
+	format     outputFormat
+}
+
+// marketRow is one search result in the machine-readable formats. AddedAt is
+// set for events that are already saved.
+type marketRow struct {
+	Rank int `json:"rank"`
+	types.SavedMarket
+	Volume  float64 `json:"volume"`
+	EndDate string  `json:"endDate"`
+	Saved   bool    `json:"saved"`
+}
+
+func cmdMarkets(args []string, opts marketsOptions) error {
+	format := opts.format
+	out := format.status()
+
+	selection := opts.selection
+	if opts.all || (selection == "" && opts.yes) {
+		selection = "all"
+	}
+	interactive := selection == "" && !format.machine()
+	if interactive && !stdinIsTerminal() {
+		return fmt.Errorf("stdin is not a terminal; choose events with --select, --all or --yes")
+	}
+
 	var query string
 	if len(args) > 0 {
 		query = strings.Join(args, " ")
-	} else {
-		fmt.Print("Enter search query: ")
-		reader := bufio.NewReader(os.Stdin)
-		query, _ = reader.ReadString('\n')
-		query = strings.TrimSpace(query)
+	} else if interactive {
+		query = prompt("Enter search query: ")
 	}
 
 	if query == "" {
-		fmt.Println("No query provided")
-		return
+		return fmt.Errorf("no query provided")
 	}
 
 	cfg := config.Load()
 	apiClient := api.New(cfg)
 
-	fmt.Printf("\nSearching for: %s\n\n", query)
+	fmt.Fprintf(out, "\nSearching for: %s\n\n", query)
 
 	markets, err := apiClient.SearchMarkets(query)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		return
-	}
-
-	if len(markets) == 0 {
-		fmt.Println("No markets found")
-		return
+		return err
 	}
 
 	// Group by event slug to avoid duplicates
 	eventMap := make(map[string]types.Market)
 	for _, m := range markets {
 		eventSlug := m.EventSlug()
-		if eventSlug != "" {
+		if eventSlug != "" && matchesMarketFilters(m, opts) {
 			// Keep first market per event (has the event info)
 			if _, exists := eventMap[eventSlug]; !exists {
 				eventMap[eventSlug] = m
@@ -324,6 +796,18 @@ func cmdMarkets(args []string) {
 		}
 	}
 
+	if len(eventMap) == 0 {
+		if len(markets) == 0 {
+			fmt.Fprintln(out, "No markets found")
+		} else {
+			fmt.Fprintf(out, "None of %d markets match the filters\n", len(markets))
+		}
+		if format.machine() {
+			return format.write([]marketRow{})
+		}
+		return nil
+	}
+
 	// Load existing markets to check status
 	savedMarkets, _ := storage.LoadMarkets()
 	savedSlugs := make(map[string]bool)
@@ -336,23 +820,56 @@ func cmdMarkets(args []string) {
 	for eventSlug := range eventMap {
 		slugList = append(slugList, eventSlug)
 	}
-	
+
 	// Sort slugList by volume (highest first)
 	sort.Slice(slugList, func(i, j int) bool {
-		volI, _ := strconv.ParseFloat(eventMap[slugList[i]].Volume, 64)
-		volJ, _ := strconv.ParseFloat(eventMap[slugList[j]].Volume, 64)
-		return volI > volJ
+		mi, mj := eventMap[slugList[i]], eventMap[slugList[j]]
+		return mi.EventVolume() > mj.EventVolume()
 	})
 
+	if !format.machine() {
+		printMarketTable(slugList, eventMap, savedSlugs)
+	}
+
+	if interactive {
+		selection = prompt("Enter numbers to add (comma-separated), 'all', or press Enter to cancel: ")
+	}
+
+	if selection != "" {
+		if err := addSearchedEvents(slugList, eventMap, savedSlugs, selection, out); err != nil {
+			return err
+		}
+	}
+
+	if format.machine() {
+		return writeMarketRows(slugList, eventMap, format)
+	}
+	return nil
+}
+
+// matchesMarketFilters reports whether m passes --min-volume, checked against
+// its event's total volume, and --ends-before. With --ends-before, markets without an end date are left out.
+func matchesMarketFilters(m types.Market, opts marketsOptions) bool {
+	if opts.minVolume > 0 && m.EventVolume() < opts.minVolume {
+		return false
+	}
+	if !opts.endsBefore.IsZero() {
+		end, err := time.Parse(time.RFC3339, m.EndDate)
+		if err != nil || !end.Before(opts.endsBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+func printMarketTable(slugList []string, eventMap map[string]types.Market, savedSlugs map[string]bool) {
 	// Display events
 	fmt.Println("  #  | Event                                                                | Volume       | End Date")
 	fmt.Println("-----+----------------------------------------------------------------------+--------------+------------")
 
-	idx := 0
-	for _, eventSlug := range slugList {
-		idx++
+	for i, eventSlug := range slugList {
 		m := eventMap[eventSlug]
-		
+
 		// Format end date
 		endDate := "N/A"
 		if m.EndDate != "" {
@@ -360,41 +877,31 @@ func cmdMarkets(args []string) {
 				endDate = t.Format("Jan 02 2006")
 			}
 		}
-		
+
 		// Show saved status with checkmark
 		title := m.EventTitle()
 		if savedSlugs[eventSlug] {
 			title = "✓ " + title
 		}
-		
+
 		// Expand title width to 68 characters
 		if len(title) > 68 {
 			title = title[:65] + "..."
 		}
 
-		vol := 0.0
-		if v, err := strconv.ParseFloat(m.Volume, 64); err == nil {
-			vol = v
-		}
-
-		fmt.Printf("  %2d | %-68s | %12s | %s\n", idx, title, formatUSD(vol), endDate)
+		fmt.Printf("  %2d | %-68s | %12s | %s\n", i+1, title, formatUSD(m.EventVolume()), endDate)
 	}
-
 	fmt.Println()
-	fmt.Print("Enter numbers to add (comma-separated), 'all', or press Enter to cancel: ")
-	reader := bufio.NewReader(os.Stdin)
-	selection, _ := reader.ReadString('\n')
-	selection = strings.TrimSpace(selection)
-
-	if selection == "" {
-		return
-	}
+}
 
+// addSearchedEvents saves the events picked by selection, 'all' or
+// comma-separated numbers from the listed order, that aren't saved yet.
+func addSearchedEvents(slugList []string, eventMap map[string]types.Market, savedSlugs map[string]bool, selection string, out io.Writer) error {
 	var toAdd []string
 
 	switch {
 	case strings.ToLower(selection) == "all":
-		for eventSlug := range eventMap {
+		for _, eventSlug := range slugList {
 			if !savedSlugs[eventSlug] {
 				toAdd = append(toAdd, eventSlug)
 			}
@@ -403,21 +910,22 @@ func cmdMarkets(args []string) {
 		nums := strings.Split(selection, ",")
 		for _, n := range nums {
 			idx, err := strconv.Atoi(strings.TrimSpace(n))
-			if err == nil && idx > 0 && idx <= len(slugList) {
-				eventSlug := slugList[idx-1]
-				if !savedSlugs[eventSlug] {
-					toAdd = append(toAdd, eventSlug)
-				}
+			if err != nil || idx < 1 || idx > len(slugList) {
+				return fmt.Errorf("invalid selection %q (use numbers 1-%d, comma-separated)", strings.TrimSpace(n), len(slugList))
+			}
+			eventSlug := slugList[idx-1]
+			if !savedSlugs[eventSlug] {
+				toAdd = append(toAdd, eventSlug)
 			}
 		}
 	}
 
 	if len(toAdd) == 0 {
-		fmt.Println("No new events to add.")
-		return
+		fmt.Fprintln(out, "No new events to add.")
+		return nil
 	}
 
-	fmt.Printf("\nAdding %d events...\n", len(toAdd))
+	fmt.Fprintf(out, "\nAdding %d events...\n", len(toAdd))
 
 	for _, eventSlug := range toAdd {
 		m := eventMap[eventSlug]
@@ -427,30 +935,70 @@ func cmdMarkets(args []string) {
 			displayTitle = displayTitle[:50] + "..."
 		}
 
-		added, _ := storage.AddMarket(types.SavedMarket{
+		added, err := storage.AddMarket(types.SavedMarket{
 			Slug:    eventSlug,
 			Title:   eventTitle,
 			AddedAt: time.Now().Format(time.RFC3339),
 		})
+		if err != nil {
+			return err
+		}
 
 		if added {
-			fmt.Printf("  ✓ Added: %s\n", displayTitle)
+			savedSlugs[eventSlug] = true
+			fmt.Fprintf(out, "  ✓ Added: %s\n", displayTitle)
 		}
 	}
 
-	markets2, _ := storage.LoadMarkets()
-	fmt.Printf("\nNow tracking %d events total.\n", len(markets2))
+	markets, _ := storage.LoadMarkets()
+	fmt.Fprintf(out, "\nNow tracking %d events total.\n", len(markets))
+	return nil
+}
+
+// writeMarketRows prints the listed events with their saved state as it is
+// after any additions.
+func writeMarketRows(slugList []string, eventMap map[string]types.Market, format outputFormat) error {
+	savedMarkets, _ := storage.LoadMarkets()
+	addedAt := make(map[string]string)
+	for _, sm := range savedMarkets {
+		addedAt[sm.Slug] = sm.AddedAt
+	}
+
+	rows := make([]marketRow, 0, len(slugList))
+	for i, eventSlug := range slugList {
+		m := eventMap[eventSlug]
+		_, saved := addedAt[eventSlug]
+		rows = append(rows, marketRow{
+			Rank: i + 1,
+			SavedMarket: types.SavedMarket{
+				Slug:    eventSlug,
+				Title:   m.EventTitle(),
+				AddedAt: addedAt[eventSlug],
+			},
+			Volume:  m.EventVolume(),
+			EndDate: m.EndDate,
+			Saved:   saved,
+		})
+	}
+	return format.write(rows)
 }
 
 // ============= ADD-MARKET COMMAND =============
 
-func cmdAddMarket(args []string) {
-	if len(args) < 1 {
-		fmt.Println("Usage: polymarket-tool add-market <url-or-slug>")
-		os.Exit(1)
+func newAddMarketCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-market <url-or-slug>",
+		Short: "Add a market by URL or slug",
+		Example: `  polymarket-tool add-market https://polymarket.com/event/fed-decision-in-january
+  polymarket-tool add-market fed-decision-in-january`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdAddMarket(args[0])
+		},
 	}
+}
 
-	input := args[0]
+func cmdAddMarket(input string) error {
 	slug := parseMarketURL(input)
 	if slug == "" {
 		slug = input
@@ -463,8 +1011,7 @@ func cmdAddMarket(args []string) {
 
 	event, err := apiClient.GetEventBySlug(slug)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("\nFound: %s\n", event.Title)
@@ -490,8 +1037,7 @@ func cmdAddMarket(args []string) {
 	})
 
 	if err != nil {
-		fmt.Printf("Error saving: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("saving: %w", err)
 	}
 
 	if added {
@@ -502,6 +1048,7 @@ func cmdAddMarket(args []string) {
 
 	markets, _ := storage.LoadMarkets()
 	fmt.Printf("Current watched markets: %d\n", len(markets))
+	return nil
 }
 
 func parseMarketURL(input string) string {
@@ -515,12 +1062,81 @@ func parseMarketURL(input string) string {
 
 // ============= DISCOVER-WHALES COMMAND =============
 
-func cmdDiscoverWhales(args []string) {
+func newDiscoverWhalesCmd() *cobra.Command {
+	var opts discoverOptions
+	cmd := &cobra.Command{
+		Use:   "discover-whales [selection]",
+		Short: "Add whales from the leaderboard (top10, all, 1,2,3)",
+		Long: `Show the top traders by PnL and add some of them as whales. Pick them with a
+selection argument, or interactively when it is left out: 'topN' for the
+first N listed, 'all', or comma-separated ranks. --min-pnl and --min-volume
+narrow the list first, and --window ranks PnL over a day, week, month or all
+time. --yes adds every listed trader when no selection is given. Without a
+selection or --yes stdin must be a terminal. With --format json,
+ndjson or csv the leaderboard is printed without prompting, after adding any
+selection.`,
+		Example: `  polymarket-tool discover-whales top10
+  polymarket-tool discover-whales 1,2,3 --limit 50
+  polymarket-tool discover-whales all --window week --min-pnl 100000 --min-volume 1e6
+  polymarket-tool discover-whales --limit 100 --format json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: firstArg(fixedValues("top5", "top10", "top20", "all")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.selection = args[0]
+			}
+			return cmdDiscoverWhales(opts)
+		},
+	}
+	cmd.Flags().IntVar(&opts.limit, "limit", 30, "number of leaderboard entries to fetch")
+	cmd.Flags().Float64Var(&opts.minPnL, "min-pnl", 0, "only traders with at least this PnL in USD")
+	cmd.Flags().Float64Var(&opts.minVolume, "min-volume", 0, "only traders with at least this volume in USD")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "don't ask: add the selection, or every listed trader")
+	cmd.Flags().StringVar(&opts.window, "window", "", "leaderboard period: "+strings.Join(api.LeaderboardWindows, ", ")+" (default: the API's)")
+	cmd.RegisterFlagCompletionFunc("window", fixedValues(api.LeaderboardWindows...))
+	addFormatFlag(cmd, &opts.format)
+	return cmd
+}
+
+type discoverOptions struct {
+	selection string
+	yes       bool
+	limit     int
+	minPnL    float64
+	minVolume float64
+	window    string
+	format    outputFormat
+}
+
+// leaderboardRow is one leaderboard entry in the machine-readable formats,
+// shaped like the whale it would be saved as.
+type leaderboardRow struct {
+	Rank int `json:"rank"`
+	types.Whale
+	Tracked bool `json:"tracked"`
+}
+
+func cmdDiscoverWhales(opts discoverOptions) error {
+	if opts.limit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+	if opts.window != "" && !slices.Contains(api.LeaderboardWindows, opts.window) {
+		return fmt.Errorf("invalid --window %q (%s)", opts.window, strings.Join(api.LeaderboardWindows, ", "))
+	}
+	format, selection := opts.format, opts.selection
+	if selection == "" && opts.yes {
+		selection = "all"
+	}
+	if selection == "" && !format.machine() && !stdinIsTerminal() {
+		return fmt.Errorf("stdin is not a terminal; pass a selection such as top10, all or 1,2,3, or --yes")
+	}
+
 	cfg := config.Load()
 	apiClient := api.New(cfg)
+	out := format.status()
 
-	fmt.Println("Discover Whales from Leaderboard")
-	fmt.Println("=================================\n")
+	fmt.Fprintln(out, "Discover Whales from Leaderboard")
+	fmt.Fprint(out, "=================================\n\n")
 
 	existingWhales, _ := storage.LoadWhales()
 	existingAddrs := make(map[string]bool)
@@ -528,23 +1144,53 @@ func cmdDiscoverWhales(args []string) {
 		existingAddrs[strings.ToLower(w.Address)] = true
 	}
 
-	fmt.Printf("Currently tracking %d whales\n\n", len(existingWhales))
-	fmt.Println("Fetching leaderboard...\n")
+	fmt.Fprintf(out, "Currently tracking %d whales\n\n", len(existingWhales))
+	fmt.Fprint(out, "Fetching leaderboard...\n\n")
 
-	leaderboard, err := apiClient.GetLeaderboard(30)
+	entries, err := apiClient.GetLeaderboard(opts.window, opts.limit)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	fmt.Println("Top traders by PnL:\n")
-	fmt.Println("  #  | Name                 | PnL          | Volume       | Status")
-	fmt.Println("-----+----------------------+--------------+--------------+--------")
+	var leaderboard []types.LeaderboardEntry
+	for _, e := range entries {
+		if e.PnL >= opts.minPnL && e.Volume >= opts.minVolume {
+			leaderboard = append(leaderboard, e)
+		}
+	}
+	if len(leaderboard) < len(entries) {
+		fmt.Fprintf(out, "%d of %d traders match the filters\n\n", len(leaderboard), len(entries))
+	}
 
-	for _, entry := range leaderboard {
-		status := ""
-		if existingAddrs[strings.ToLower(entry.ProxyWallet)] {
-			status = "✓ tracked"
+	if format.machine() {
+		if err := addLeaderboardWhales(leaderboard, selection, existingAddrs, out); err != nil {
+			return err
+		}
+		whales, _ := storage.LoadWhales()
+		tracked := make(map[string]bool)
+		for _, w := range whales {
+			tracked[strings.ToLower(w.Address)] = true
+		}
+		rows := make([]leaderboardRow, 0, len(leaderboard))
+		for _, e := range leaderboard {
+			rank, _ := strconv.Atoi(e.Rank)
+			rows = append(rows, leaderboardRow{
+				Rank:    rank,
+				Whale:   leaderboardWhale(e),
+				Tracked: tracked[strings.ToLower(e.ProxyWallet)],
+			})
+		}
+		return format.write(rows)
+	}
+
+	fmt.Println("Top traders by PnL:\n")
+	fmt.Println("  #  | Name                 | PnL          | Volume       | Status")
+	fmt.Println("-----+----------------------+--------------+--------------+--------")
+
+	for _, entry := range leaderboard {
+		status := ""
+		if existingAddrs[strings.ToLower(entry.ProxyWallet)] {
+			status = "✓ tracked"
 		}
 		name := entry.UserName
 		if name == "" {
@@ -559,18 +1205,18 @@ func cmdDiscoverWhales(args []string) {
 
 	fmt.Println()
 
-	var selection string
-	if len(args) > 0 {
-		selection = args[0]
-	} else {
-		fmt.Print("Enter ranks to add (comma-separated), 'all', or 'topN': ")
-		reader := bufio.NewReader(os.Stdin)
-		selection, _ = reader.ReadString('\n')
-		selection = strings.TrimSpace(selection)
+	if selection == "" {
+		selection = prompt("Enter ranks to add (comma-separated), 'all', or 'topN': ")
 	}
 
+	return addLeaderboardWhales(leaderboard, selection, existingAddrs, os.Stdout)
+}
+
+// addLeaderboardWhales saves the leaderboard entries picked by selection that
+// aren't tracked yet, reporting progress to out.
+func addLeaderboardWhales(leaderboard []types.LeaderboardEntry, selection string, existingAddrs map[string]bool, out io.Writer) error {
 	if selection == "" {
-		return
+		return nil
 	}
 
 	var toAdd []types.LeaderboardEntry
@@ -583,9 +1229,12 @@ func cmdDiscoverWhales(args []string) {
 			}
 		}
 	case strings.HasPrefix(strings.ToLower(selection), "top"):
-		n, _ := strconv.Atoi(selection[3:])
-		if n == 0 {
-			n = 10
+		n := 10
+		if selection[3:] != "" {
+			var err error
+			if n, err = strconv.Atoi(selection[3:]); err != nil || n < 1 {
+				return fmt.Errorf("invalid selection %q (use e.g. top10)", selection)
+			}
 		}
 		for i, e := range leaderboard {
 			if i >= n {
@@ -599,7 +1248,11 @@ func cmdDiscoverWhales(args []string) {
 		ranks := strings.Split(selection, ",")
 		rankSet := make(map[string]bool)
 		for _, r := range ranks {
-			rankSet[strings.TrimSpace(r)] = true
+			r = strings.TrimSpace(r)
+			if _, err := strconv.Atoi(r); err != nil {
+				return fmt.Errorf("invalid selection %q (use topN, all or ranks like 1,2,3)", selection)
+			}
+			rankSet[r] = true
 		}
 		for _, e := range leaderboard {
 			if rankSet[e.Rank] && !existingAddrs[strings.ToLower(e.ProxyWallet)] {
@@ -609,50 +1262,114 @@ func cmdDiscoverWhales(args []string) {
 	}
 
 	if len(toAdd) == 0 {
-		fmt.Println("No new whales to add.")
-		return
+		fmt.Fprintln(out, "No new whales to add.")
+		return nil
 	}
 
-	fmt.Printf("\nAdding %d whales...\n", len(toAdd))
+	fmt.Fprintf(out, "\nAdding %d whales...\n", len(toAdd))
 
 	for _, entry := range toAdd {
-		name := entry.UserName
-		if name == "" {
-			name = "Rank #" + entry.Rank
-		}
+		whale := leaderboardWhale(entry)
+		whale.AddedAt = time.Now().Format(time.RFC3339)
 
-		whale := types.Whale{
-			Address: entry.ProxyWallet,
-			Name:    name,
-			PnL:     entry.PnL,
-			Volume:  entry.Volume,
-			AddedAt: time.Now().Format(time.RFC3339),
+		added, err := storage.AddWhale(whale)
+		if err != nil {
+			return err
 		}
-
-		if added, _ := storage.AddWhale(whale); added {
-			fmt.Printf("  ✓ Added: %s (%s PnL)\n", name, formatUSD(entry.PnL))
+		if added {
+			fmt.Fprintf(out, "  ✓ Added: %s (%s PnL)\n", whale.Name, formatUSD(entry.PnL))
 		}
 	}
 
 	whales, _ := storage.LoadWhales()
-	fmt.Printf("\nNow tracking %d whales total.\n", len(whales))
+	fmt.Fprintf(out, "\nNow tracking %d whales total.\n", len(whales))
+	return nil
+}
+
+// leaderboardWhale is the whale a leaderboard entry is saved as.
+func leaderboardWhale(entry types.LeaderboardEntry) types.Whale {
+	name := entry.UserName
+	if name == "" {
+		name = "Rank #" + entry.Rank
+	}
+	return types.Whale{
+		Address: entry.ProxyWallet,
+		Name:    name,
+		PnL:     entry.PnL,
+		Volume:  entry.Volume,
+	}
 }
 
 // ============= WHALE-TRADES COMMAND =============
 
-func cmdWhaleTrades(args []string) {
+func newWhaleTradesCmd() *cobra.Command {
+	var opts whaleTradesOptions
+	cmd := &cobra.Command{
+		Use:   "whale-trades [name|index] [limit]",
+		Short: "View recent trades for tracked whales",
+		Long: `View recent trades for every tracked whale, or for those whose name or
+address contains name, or the whale at index in 'list whales'.`,
+		Example: `  polymarket-tool whale-trades
+  polymarket-tool whale-trades beachboy4 --limit 50
+  polymarket-tool whale-trades 1 --since 1d
+  polymarket-tool whale-trades --since 1d --format ndjson | jq .usdcSize`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: firstArg(completeWhaleNames),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.selection = args[0]
+			}
+			if len(args) > 1 {
+				if cmd.Flags().Changed("limit") {
+					return fmt.Errorf("give the limit either as an argument or with --limit, not both")
+				}
+				l, err := strconv.Atoi(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid limit %q", args[1])
+				}
+				opts.limit = l
+			}
+			return cmdWhaleTrades(opts)
+		},
+	}
+	cmd.Flags().IntVar(&opts.limit, "limit", 20, "recent activity entries to fetch per whale")
+	cmd.Flags().Var((*dayDuration)(&opts.since), "since", "only trades newer than this, e.g. 6h or 2d")
+	addFormatFlag(cmd, &opts.format)
+	return cmd
+}
+
+type whaleTradesOptions struct {
+	selection string
+	limit     int
+	since     time.Duration
+	format    outputFormat
+}
+
+// whaleTradeRow is one whale trade in the machine-readable formats.
+type whaleTradeRow struct {
+	WhaleName string `json:"whaleName"`
+	types.UserActivity
+}
+
+func cmdWhaleTrades(opts whaleTradesOptions) error {
+	if opts.limit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+
+	out := opts.format.status()
 	whales, _ := storage.LoadWhales()
 
 	if len(whales) == 0 {
-		fmt.Println("No whales tracked. Run: polymarket-tool discover-whales")
-		return
+		fmt.Fprintln(out, "No whales tracked. Run: polymarket-tool discover-whales")
+		if opts.format.machine() {
+			return opts.format.write([]whaleTradeRow{})
+		}
+		return nil
 	}
 
 	selectedWhales := whales
-	limit := 20
 
-	if len(args) > 0 {
-		selection := args[0]
+	if selection := opts.selection; selection != "" {
 		idx, err := strconv.Atoi(selection)
 		if err == nil && idx > 0 && idx <= len(whales) {
 			selectedWhales = []types.Whale{whales[idx-1]}
@@ -664,46 +1381,55 @@ func cmdWhaleTrades(args []string) {
 					filtered = append(filtered, w)
 				}
 			}
-			if len(filtered) > 0 {
-				selectedWhales = filtered
-			} else {
-				fmt.Printf("No whale found matching: %s\n", selection)
-				fmt.Println("\nTracked whales:")
+			if len(filtered) == 0 {
+				fmt.Fprintln(out, "Tracked whales:")
 				for i, w := range whales {
-					fmt.Printf("  %d. %s\n", i+1, w.Name)
+					fmt.Fprintf(out, "  %d. %s\n", i+1, w.Name)
 				}
-				return
+				fmt.Fprintln(out)
+				return fmt.Errorf("no whale found matching: %s", selection)
 			}
-		}
-	}
-
-	if len(args) > 1 {
-		if l, err := strconv.Atoi(args[1]); err == nil {
-			limit = l
+			selectedWhales = filtered
 		}
 	}
 
 	cfg := config.Load()
 	apiClient := api.New(cfg)
 
+	failed := 0
+	rows := []whaleTradeRow{}
 	for _, whale := range selectedWhales {
-		fmt.Printf("\n%s\n", strings.Repeat("=", 70))
-		fmt.Printf("🐋 %s\n", whale.Name)
-		fmt.Printf("   %s\n", whale.Address)
-		fmt.Printf("   PnL: %s | Volume: %s\n", formatUSD(whale.PnL), formatUSD(whale.Volume))
-		fmt.Println(strings.Repeat("=", 70))
+		if !opts.format.machine() {
+			fmt.Printf("\n%s\n", strings.Repeat("=", 70))
+			fmt.Printf("🐋 %s\n", whale.Name)
+			fmt.Printf("   %s\n", whale.Address)
+			fmt.Printf("   PnL: %s | Volume: %s\n", formatUSD(whale.PnL), formatUSD(whale.Volume))
+			fmt.Println(strings.Repeat("=", 70))
+		}
 
-		activity, err := apiClient.GetUserActivity(whale.Address, limit)
+		activity, err := apiClient.GetUserActivity(whale.Address, opts.limit)
 		if err != nil {
-			fmt.Printf("\n  Error fetching activity: %v\n", err)
+			fmt.Fprintf(out, "\n  Error fetching activity for %s: %v\n", whale.Name, err)
+			failed++
 			continue
 		}
 
 		var trades []types.UserActivity
 		for _, a := range activity {
-			if a.Type == "TRADE" {
-				trades = append(trades, a)
+			if a.Type != "TRADE" {
+				continue
+			}
+			if opts.since > 0 && time.Since(time.Unix(a.Timestamp, 0)) > opts.since {
+				continue
 			}
+			trades = append(trades, a)
+		}
+
+		if opts.format.machine() {
+			for _, t := range trades {
+				rows = append(rows, whaleTradeRow{WhaleName: whale.Name, UserActivity: t})
+			}
+			continue
 		}
 
 		if len(trades) == 0 {
@@ -738,98 +1464,840 @@ func cmdWhaleTrades(args []string) {
 		fmt.Printf("  Total: %s across %d markets\n", formatUSD(totalValue), len(marketSet))
 	}
 
-	fmt.Println()
+	if opts.format.machine() {
+		if err := opts.format.write(rows); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+	}
+	if failed > 0 {
+		return fmt.Errorf("activity for %d of %d whales could not be fetched", failed, len(selectedWhales))
+	}
+	return nil
 }
 
 // ============= LIST COMMAND =============
 
-func cmdList(args []string) {
-	if len(args) < 1 {
-		fmt.Println("Usage: polymarket-tool list <whales|markets|clear-whales|clear-markets|remove-whale|remove-market>")
-		return
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List and manage tracked whales and saved markets",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  requireSubcommand,
 	}
 
-	switch args[0] {
-	case "whales":
-		whales, _ := storage.LoadWhales()
-		fmt.Printf("\nTracked Whales (%d):\n", len(whales))
-		fmt.Println(strings.Repeat("=", 70))
-
-		if len(whales) == 0 {
-			fmt.Println("No whales tracked. Run: polymarket-tool discover-whales")
-		} else {
-			for _, w := range whales {
-				fmt.Printf("  %s\n", w.Name)
-				fmt.Printf("    Address: %s\n", w.Address)
-				fmt.Printf("    PnL: %s | Volume: %s\n", formatUSD(w.PnL), formatUSD(w.Volume))
-				if w.Note != "" {
-					fmt.Printf("    Note: %s\n", w.Note)
+	var (
+		yes    bool
+		format outputFormat
+	)
+	listWhales := &cobra.Command{
+		Use:   "whales",
+		Short: "List tracked whales",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdListWhales(format)
+		},
+	}
+	addFormatFlag(listWhales, &format)
+
+	listMarkets := &cobra.Command{
+		Use:   "markets",
+		Short: "List saved markets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdListMarkets(format)
+		},
+	}
+	addFormatFlag(listMarkets, &format)
+
+	clearWhales := &cobra.Command{
+		Use:   "clear-whales",
+		Short: "Remove all tracked whales",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdClearWhales(yes)
+		},
+	}
+	clearWhales.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask for confirmation")
+
+	clearMarkets := &cobra.Command{
+		Use:   "clear-markets",
+		Short: "Remove all saved markets",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdClearMarkets(yes)
+		},
+	}
+	clearMarkets.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask for confirmation")
+
+	cmd.AddCommand(
+		listWhales,
+		listMarkets,
+		&cobra.Command{
+			Use:               "remove-whale <address>",
+			Short:             "Remove a tracked whale",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: firstArg(completeWhaleAddresses),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				removed, err := storage.RemoveWhale(args[0])
+				if err != nil {
+					return err
 				}
-				fmt.Println()
-			}
+				if !removed {
+					return fmt.Errorf("no tracked whale with address %s", args[0])
+				}
+				fmt.Println("✓ Whale removed")
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:               "remove-market <slug>",
+			Short:             "Remove a saved market",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: firstArg(completeMarketSlugs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				slug := args[0]
+				if s := parseMarketURL(slug); s != "" {
+					slug = s
+				}
+				removed, err := storage.RemoveMarket(slug)
+				if err != nil {
+					return err
+				}
+				if !removed {
+					return fmt.Errorf("no saved market with slug %s", slug)
+				}
+				fmt.Println("✓ Market removed")
+				return nil
+			},
+		},
+		clearWhales,
+		clearMarkets,
+	)
+	return cmd
+}
+
+func cmdListWhales(format outputFormat) error {
+	whales, err := storage.LoadWhales()
+	if err != nil {
+		return err
+	}
+	if format.machine() {
+		if whales == nil {
+			whales = []types.Whale{}
 		}
+		return format.write(whales)
+	}
+	fmt.Printf("\nTracked Whales (%d):\n", len(whales))
+	fmt.Println(strings.Repeat("=", 70))
 
-	case "markets":
-		markets, _ := storage.LoadMarkets()
-		fmt.Printf("\nSaved Markets (%d):\n", len(markets))
-		fmt.Println(strings.Repeat("=", 70))
+	if len(whales) == 0 {
+		fmt.Println("No whales tracked. Run: polymarket-tool discover-whales")
+		return nil
+	}
+	for _, w := range whales {
+		fmt.Printf("  %s\n", w.Name)
+		fmt.Printf("    Address: %s\n", w.Address)
+		fmt.Printf("    PnL: %s | Volume: %s\n", formatUSD(w.PnL), formatUSD(w.Volume))
+		if w.Note != "" {
+			fmt.Printf("    Note: %s\n", w.Note)
+		}
+		fmt.Println()
+	}
+	return nil
+}
 
-		if len(markets) == 0 {
-			fmt.Println("No markets saved. Run: polymarket-tool add-market <url>")
-		} else {
-			for _, m := range markets {
-				fmt.Printf("  %s\n", m.Title)
-				fmt.Printf("    Slug: %s\n", m.Slug)
-				fmt.Printf("    URL: https://polymarket.com/event/%s\n", m.Slug)
-				fmt.Println()
-			}
+func cmdListMarkets(format outputFormat) error {
+	markets, err := storage.LoadMarkets()
+	if err != nil {
+		return err
+	}
+	if format.machine() {
+		if markets == nil {
+			markets = []types.SavedMarket{}
 		}
+		return format.write(markets)
+	}
+	fmt.Printf("\nSaved Markets (%d):\n", len(markets))
+	fmt.Println(strings.Repeat("=", 70))
 
-	case "remove-whale":
-		if len(args) < 2 {
-			fmt.Println("Usage: polymarket-tool list remove-whale <address>")
-			return
+	if len(markets) == 0 {
+		fmt.Println("No markets saved. Run: polymarket-tool add-market <url>")
+		return nil
+	}
+	for _, m := range markets {
+		fmt.Printf("  %s\n", m.Title)
+		fmt.Printf("    Slug: %s\n", m.Slug)
+		fmt.Printf("    URL: https://polymarket.com/event/%s\n", m.Slug)
+		fmt.Println()
+	}
+	return nil
+}
+
+func cmdClearWhales(yes bool) error {
+	whales, err := storage.LoadWhales()
+	if err != nil {
+		return err
+	}
+	if len(whales) == 0 {
+		fmt.Println("No whales tracked.")
+		return nil
+	}
+	if err := confirm(fmt.Sprintf("Remove all %d tracked whales?", len(whales)), yes); err != nil {
+		return err
+	}
+
+	count, err := storage.ClearWhales()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Cleared %d whales\n", count)
+	return nil
+}
+
+func cmdClearMarkets(yes bool) error {
+	markets, err := storage.LoadMarkets()
+	if err != nil {
+		return err
+	}
+	if len(markets) == 0 {
+		fmt.Println("No markets saved.")
+		return nil
+	}
+	if err := confirm(fmt.Sprintf("Remove all %d saved markets?", len(markets)), yes); err != nil {
+		return err
+	}
+
+	count, err := storage.ClearMarkets()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Cleared %d markets\n", count)
+	return nil
+}
+
+// ============= MUTE COMMANDS =============
+
+func newMuteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mute",
+		Short: "Stop notifying for a market or wallet",
+		Long: `Stop notifying for a market or wallet, for a duration (e.g. 2h, 1d) or
+indefinitely. Muted detections are still recorded, and a running start picks
+up changes within a few seconds.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: requireSubcommand,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:               "market <slug|url> [duration]",
+			Short:             "Mute a market",
+			Example:           "  polymarket-tool mute market fed-decision-in-january 2h",
+			Args:              cobra.RangeArgs(1, 2),
+			ValidArgsFunction: firstArg(completeMarketSlugs),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmdMute(types.MuteMarket, args)
+			},
+		},
+		&cobra.Command{
+			Use:               "wallet <address> [duration]",
+			Short:             "Mute a wallet",
+			Example:           "  polymarket-tool mute wallet 0x123... 1d",
+			Args:              cobra.RangeArgs(1, 2),
+			ValidArgsFunction: firstArg(completeWhaleAddresses),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmdMute(types.MuteWallet, args)
+			},
+		},
+	)
+	return cmd
+}
+
+func cmdMute(kind string, args []string) error {
+	target := args[0]
+	if kind == types.MuteMarket {
+		if slug := parseMarketURL(target); slug != "" {
+			target = slug
 		}
-		if removed, _ := storage.RemoveWhale(args[1]); removed {
-			fmt.Println("✓ Whale removed")
-		} else {
-			fmt.Println("Whale not found")
+	}
+
+	mute := types.Mute{
+		Kind:    kind,
+		Target:  target,
+		AddedAt: time.Now().Format(time.RFC3339),
+	}
+
+	if len(args) > 1 {
+		d, err := parseDuration(args[1])
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid duration: %s (use e.g. 30m, 2h, 1d)", args[1])
 		}
+		mute.Until = time.Now().Add(d).Format(time.RFC3339)
+	}
 
-	case "remove-market":
-		if len(args) < 2 {
-			fmt.Println("Usage: polymarket-tool list remove-market <slug>")
-			return
+	if err := storage.AddMute(mute); err != nil {
+		return fmt.Errorf("saving: %w", err)
+	}
+
+	if mute.Until != "" {
+		fmt.Printf("✓ Muted %s %s until %s\n", kind, target, mute.Until)
+	} else {
+		fmt.Printf("✓ Muted %s %s\n", kind, target)
+	}
+	return nil
+}
+
+func newUnmuteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unmute",
+		Short: "Remove a mute",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  requireSubcommand,
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:               "market <slug|url>",
+			Short:             "Unmute a market",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: firstArg(completeMutes(types.MuteMarket)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmdUnmute(types.MuteMarket, args[0])
+			},
+		},
+		&cobra.Command{
+			Use:               "wallet <address>",
+			Short:             "Unmute a wallet",
+			Args:              cobra.ExactArgs(1),
+			ValidArgsFunction: firstArg(completeMutes(types.MuteWallet)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return cmdUnmute(types.MuteWallet, args[0])
+			},
+		},
+	)
+	return cmd
+}
+
+func cmdUnmute(kind, target string) error {
+	if kind == types.MuteMarket {
+		if slug := parseMarketURL(target); slug != "" {
+			target = slug
 		}
-		if removed, _ := storage.RemoveMarket(args[1]); removed {
-			fmt.Println("✓ Market removed")
-		} else {
-			fmt.Println("Market not found")
+	}
+
+	removed, err := storage.RemoveMute(kind, target)
+	if err != nil {
+		return fmt.Errorf("saving: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("%s %s is not muted", kind, target)
+	}
+	fmt.Println("✓ Mute removed")
+	return nil
+}
+
+func newMutesCmd() *cobra.Command {
+	var format outputFormat
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List active mutes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdMutes(format)
+		},
+	}
+	cmd := &cobra.Command{
+		Use:   "mutes",
+		Short: "List active mutes",
+		Args:  cobra.NoArgs,
+		RunE:  list.RunE,
+	}
+	addFormatFlag(list, &format)
+	addFormatFlag(cmd, &format)
+	cmd.AddCommand(list)
+	return cmd
+}
+
+func cmdMutes(format outputFormat) error {
+	mutes, err := storage.LoadMutes()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	active := []types.Mute{}
+	for _, m := range mutes {
+		if !m.Expired(now) {
+			active = append(active, m)
 		}
+	}
+	if format.machine() {
+		return format.write(active)
+	}
+
+	fmt.Printf("\nActive Mutes (%d):\n", len(active))
+	fmt.Println(strings.Repeat("=", 70))
+
+	if len(active) == 0 {
+		fmt.Println("Nothing muted. Run: polymarket-tool mute market <slug> [duration]")
+		return nil
+	}
+
+	for _, m := range active {
+		until := "indefinitely"
+		if m.Until != "" {
+			until = "until " + m.Until
+		}
+		fmt.Printf("  %-6s %s\n", m.Kind, m.Target)
+		fmt.Printf("         %s\n", until)
+	}
+	fmt.Println()
+	return nil
+}
+
+// ============= REPORT COMMAND =============
+
+func newReportCmd() *cobra.Command {
+	var opts reportOptions
+	cmd := &cobra.Command{
+		Use:   "report [daily|weekly]",
+		Short: "Build an activity report for saved markets and whales",
+		Example: `  polymarket-tool report
+  polymarket-tool report weekly --format html > weekly.html
+  polymarket-tool report daily --out reports --format markdown,html,json
+  polymarket-tool report weekly --sink execs`,
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{report.Daily, report.Weekly},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			period := report.Daily
+			if len(args) > 0 {
+				period = args[0]
+			}
+			return cmdReport(period, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.format, "format", report.FormatMarkdown, "output format(s): markdown, html, json (comma-separated with --out)")
+	cmd.Flags().StringVar(&opts.outDir, "out", "", "write the report to this directory instead of stdout")
+	cmd.Flags().StringVar(&opts.sinks, "sink", "", "deliver through these notifier sinks (comma-separated, 'all' for every sink)")
+	cmd.MarkFlagDirname("out")
+	cmd.RegisterFlagCompletionFunc("format", fixedValues(report.FormatMarkdown, "html", "json"))
+	return cmd
+}
+
+type reportOptions struct {
+	format string
+	outDir string
+	sinks  string
+}
+
+func cmdReport(period string, opts reportOptions) error {
+	if _, err := report.PeriodLength(period); err != nil {
+		return err
+	}
 
-	case "clear-markets":
-		count, err := storage.ClearMarkets()
+	cfg := config.Load()
+	apiClient := api.New(cfg)
+
+	whales, _ := storage.LoadWhales()
+	savedMarkets, _ := storage.LoadMarkets()
+	fmt.Fprintf(os.Stderr, "Building %s report for %d events and %d whales...\n", period, len(savedMarkets), len(whales))
+
+	r, err := report.Build(apiClient, period, time.Now(), savedMarkets, whales)
+	if err != nil {
+		return err
+	}
+
+	formats := strings.Split(opts.format, ",")
+
+	if opts.outDir == "" && opts.sinks == "" {
+		out, err := r.Render(formats[0])
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			return
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	if opts.outDir != "" {
+		paths, err := writeReport(r, opts.outDir, formats)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("✓ Cleared %d markets\n", count)
+		for _, p := range paths {
+			fmt.Printf("✓ Wrote %s\n", p)
+		}
+	}
 
-	case "clear-whales":
-		count, err := storage.ClearWhales()
+	if opts.sinks != "" {
+		notifyCfg, err := storage.LoadNotifyConfig()
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			return fmt.Errorf("loading %s: %w", filepath.Join(storage.Dir(), "notify.json"), err)
+		}
+		notify, err := notifier.New(cfg, notifyCfg)
+		if err != nil {
+			return err
+		}
+		defer closeNotifier(notify)
+
+		var names []string
+		if opts.sinks != "all" {
+			names = strings.Split(opts.sinks, ",")
+		}
+		if err := notify.SendMessage(reportMessage(r), names); err != nil {
+			return err
+		}
+		fmt.Println("✓ Report delivered")
+	}
+	return nil
+}
+
+// runReportSchedule builds and delivers a report every time the schedule
+// comes round, for as long as the tracker runs.
+func runReportSchedule(ctx context.Context, schedule report.Schedule, cfg *config.Config, apiClient *api.Client, notify *notifier.Notifier) {
+	logger := logging.For("report").With("period", schedule.Period)
+	for {
+		timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		whales, _ := storage.LoadWhales()
+		savedMarkets, _ := storage.LoadMarkets()
+		r, err := report.Build(apiClient.WithContext(ctx), schedule.Period, time.Now(), savedMarkets, whales)
+		if ctx.Err() != nil {
 			return
 		}
-		fmt.Printf("✓ Cleared %d whales\n", count)
+		if err != nil {
+			logger.Error("build failed", "err", err)
+			continue
+		}
+
+		ok := true
+		if cfg.ReportDir != "" {
+			if _, err := writeReport(r, cfg.ReportDir, cfg.ReportFormats); err != nil {
+				logger.Error("write failed", "err", err)
+				ok = false
+			}
+		}
+		if cfg.ReportDir == "" || len(cfg.ReportSinks) > 0 {
+			if err := notify.SendMessage(reportMessage(r), cfg.ReportSinks); err != nil {
+				logger.Error("delivery failed", "err", err)
+				ok = false
+			}
+		}
+		if ok {
+			logger.Info("report sent")
+		}
+	}
+}
+
+func reportMessage(r *report.Report) notifier.Message {
+	return notifier.Message{
+		Kind:  "report",
+		Title: r.Title(),
+		Text:  r.Markdown(),
+		Data:  r,
+	}
+}
+
+func writeReport(r *report.Report, dir string, formats []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, format := range formats {
+		format = strings.TrimSpace(format)
+		out, err := r.Render(format)
+		if err != nil {
+			return paths, err
+		}
+		name := fmt.Sprintf("%s-%s.%s", r.Period, r.End.Format("2006-01-02"), report.Extensions[format])
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ============= DOCTOR COMMAND =============
+
+func newDoctorCmd() *cobra.Command {
+	var opts doctor.Options
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check config, connectivity, the data directory and sinks",
+		Long: `Check a setup before starting the tracker: environment variables, the
+notify.json sinks, API and WebSocket reachability, the data files, and a test
+message to every sink. Exits non-zero if any check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdDoctor(opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.SkipSinks, "skip-sinks", false, "don't send a test message to each sink")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 10*time.Second, "timeout for each network check")
+	return cmd
+}
+
+func cmdDoctor(opts doctor.Options) error {
+	cfg := config.Load()
+	fmt.Println("🩺 Checking polymarket-tool setup...")
+	fmt.Println()
 
+	results := doctor.Run(cfg, opts)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tCHECK\tSTATUS\tLATENCY\tDETAIL")
+	for _, r := range results {
+		latency := "-"
+		if r.Latency > 0 {
+			latency = r.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s %s\t%s\t%s\n", r.Group, r.Check, doctorIcon(r.Status), r.Status, latency, r.Detail)
+	}
+	w.Flush()
+
+	var hints []doctor.Result
+	counts := make(map[doctor.Status]int)
+	for _, r := range results {
+		counts[r.Status]++
+		if r.Hint != "" {
+			hints = append(hints, r)
+		}
+	}
+
+	if len(hints) > 0 {
+		fmt.Println("\nHow to fix:")
+		for _, r := range hints {
+			fmt.Printf("  %s %s: %s\n", doctorIcon(r.Status), r.Check, r.Hint)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d warnings, %d failed, %d skipped\n",
+		counts[doctor.Pass], counts[doctor.Warn], counts[doctor.Fail], counts[doctor.Skip])
+	if doctor.Failed(results) {
+		return fmt.Errorf("%d checks failed", counts[doctor.Fail])
+	}
+	return nil
+}
+
+func doctorIcon(s doctor.Status) string {
+	switch s {
+	case doctor.Pass:
+		return "✓"
+	case doctor.Warn:
+		return "!"
+	case doctor.Fail:
+		return "✗"
 	default:
-		fmt.Printf("Unknown list type: %s\n", args[0])
+		return "-"
+	}
+}
+
+// ============= TEST-ALERT COMMAND =============
+
+// testMarket stands in for a real market when test-alert is run without
+// --market, so it works offline.
+var testMarket = types.Market{
+	ConditionID: "0x0000000000000000000000000000000000000000000000000000000000007e57",
+	Question:    "Will this test alert reach every sink?",
+	Slug:        "polymarket-tool-test-alert",
+	Outcomes:    `["Yes","No"]`,
+	ClobTokens:  `["test-alert-yes","test-alert-no"]`,
+	Events:      []types.MarketEvent{{Slug: "polymarket-tool-test-alert", Title: "polymarket-tool test alert"}},
+}
+
+func newTestAlertCmd() *cobra.Command {
+	var opts testAlertOptions
+	cmd := &cobra.Command{
+		Use:   "test-alert",
+		Short: "Send a synthetic detection through routing to every sink",
+		Long: `Send one synthetic detection, marked [TEST], through the real notification
+path and report each sink's result and latency. Routing applies; quiet hours,
+digests, mutes and cooldowns are skipped. PagerDuty and Opsgenie open a real
+incident if the detection is routed to them.`,
+		Example: `  polymarket-tool test-alert
+  polymarket-tool test-alert --market fed-decision-in-october --whale --usd 60000
+  polymarket-tool test-alert --sink discord,hook`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdTestAlert(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.market, "market", "", "use the busiest market of this event (slug or URL) instead of a fixture")
+	cmd.Flags().BoolVar(&opts.whale, "whale", false, "attribute the trade to a tracked whale")
+	cmd.Flags().Float64Var(&opts.usd, "usd", 0, "trade value in USD (default: 5 × MIN_TRADE_USD)")
+	cmd.Flags().StringVar(&opts.side, "side", "buy", "trade side: buy or sell")
+	cmd.Flags().StringVar(&opts.sinks, "sink", "", "send to these sinks (comma-separated) instead of the routed ones")
+	cmd.RegisterFlagCompletionFunc("market", completeMarketSlugs)
+	cmd.RegisterFlagCompletionFunc("side", fixedValues("buy", "sell"))
+	return cmd
+}
+
+type testAlertOptions struct {
+	market string
+	whale  bool
+	usd    float64
+	side   string
+	sinks  string
+}
+
+func cmdTestAlert(opts testAlertOptions) error {
+	cfg := config.Load()
+	if opts.usd == 0 {
+		opts.usd = cfg.MinTradeUSD * 5
+	}
+	if opts.side != "buy" && opts.side != "sell" {
+		return fmt.Errorf("invalid --side %q (buy or sell)", opts.side)
+	}
+
+	trade := detector.SyntheticTrade{
+		Market:   &testMarket,
+		AssetID:  "test-alert-yes",
+		Side:     opts.side,
+		Price:    0.52,
+		UsdValue: opts.usd,
+	}
+
+	if opts.market != "" {
+		if err := realTestTrade(cfg, opts.market, &trade); err != nil {
+			return err
+		}
+	}
+
+	if opts.whale {
+		trade.Wallet = "0x000000000000000000000000000000000000dead"
+		trade.WhaleName = "Test Whale"
+		if whales, _ := storage.LoadWhales(); len(whales) > 0 {
+			trade.Wallet = whales[0].Address
+			trade.WhaleName = whales[0].Name
+			if trade.WhaleName == "" {
+				trade.WhaleName = whales[0].Address
+				if len(trade.WhaleName) > 10 {
+					trade.WhaleName = trade.WhaleName[:10]
+				}
+			}
+		}
+	}
+
+	notifyCfg, err := storage.LoadNotifyConfig()
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", filepath.Join(storage.Dir(), "notify.json"), err)
+	}
+	notify, err := notifier.New(cfg, notifyCfg)
+	if err != nil {
+		return err
+	}
+	defer closeNotifier(notify)
+
+	d := detector.Synthetic(cfg, trade)
+	fmt.Printf("🧪 Sending test detection: %s\n", d.Reason)
+	fmt.Printf("   %s (%s)\n", d.Market.Question, d.Market.EventSlug())
+	fmt.Printf("   %s %s @ %.1f¢ · %s · severity %s\n",
+		strings.ToUpper(d.Side), notifier.NewPayload(d).Outcome, d.Price*100, formatUSD(d.UsdValue), d.Severity)
+
+	var names []string
+	if opts.sinks != "" {
+		names = strings.Split(opts.sinks, ",")
+	}
+	deliveries, err := notify.TestDetection(d, names)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SINK\tRESULT\tLATENCY")
+	failed := false
+	for _, dl := range deliveries {
+		switch {
+		case !dl.Routed:
+			fmt.Fprintf(w, "%s\t- not routed\t-\n", dl.Sink)
+		case dl.Err != nil:
+			failed = true
+			fmt.Fprintf(w, "%s\t✗ %v\t%s\n", dl.Sink, dl.Err, dl.Latency.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(w, "%s\t✓ delivered\t%s\n", dl.Sink, dl.Latency.Round(time.Millisecond))
+		}
+	}
+	w.Flush()
+
+	if failed {
+		return fmt.Errorf("delivery failed")
+	}
+	return nil
+}
+
+// realTestTrade points trade at the busiest market of the event input names,
+// priced at its latest trade when one is available.
+func realTestTrade(cfg *config.Config, input string, trade *detector.SyntheticTrade) error {
+	slug := parseMarketURL(input)
+	if slug == "" {
+		slug = input
+	}
+
+	apiClient := api.New(cfg)
+	event, err := apiClient.GetEventBySlug(slug)
+	if err != nil {
+		return fmt.Errorf("fetching event %s: %w", slug, err)
+	}
+
+	var market *types.Market
+	bestVolume := -1.0
+	for i := range event.Markets {
+		m := &event.Markets[i]
+		vol, _ := strconv.ParseFloat(m.Volume, 64)
+		if m.ConditionID != "" && m.ClobTokens != "" && vol > bestVolume {
+			market, bestVolume = m, vol
+		}
+	}
+	if market == nil {
+		return fmt.Errorf("event %s has no tradable markets", slug)
+	}
+	if len(market.Events) == 0 {
+		market.Events = []types.MarketEvent{{Slug: event.Slug, Title: event.Title}}
+	}
+
+	var tokens []string
+	if err := json.Unmarshal([]byte(market.ClobTokens), &tokens); err != nil || len(tokens) == 0 {
+		return fmt.Errorf("market %s has no tokens", market.Slug)
+	}
+	trade.Market = market
+	trade.AssetID = tokens[0]
+
+	if trades, err := apiClient.GetMarketTrades([]string{market.ConditionID}, 1); err == nil && len(trades) > 0 {
+		trade.AssetID = trades[0].Asset
+		trade.Price = trades[0].Price
 	}
+	return nil
 }
 
 // ============= HELPERS =============
 
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...interface{}) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// parseDuration extends time.ParseDuration with a "d" suffix for days.
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
 func formatUSD(value float64) string {
 	if value >= 1_000_000 {
 		return fmt.Sprintf("$%.2fM", value/1_000_000)
@@ -855,10 +2323,3 @@ func formatTimeAgo(ts int64) string {
 		return fmt.Sprintf("%dd ago", int(diff.Hours()/24))
 	}
 }
-
-func boolStr(b bool, t, f string) string {
-	if b {
-		return t
-	}
-	return f
-}